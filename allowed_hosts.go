@@ -0,0 +1,43 @@
+package pkggodev
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrHostNotAllowed is returned, possibly wrapped, for requests to hosts
+// missing from the list given to WithAllowedHosts
+type ErrHostNotAllowed struct {
+	Host string
+}
+
+func (e ErrHostNotAllowed) Error() string {
+	return fmt.Sprintf("host '%s' is not allowed", e.Host)
+}
+
+// WithAllowedHosts restricts requests, redirects included, to the given
+// hostnames, like "pkg.go.dev" or "proxy.golang.org". Hostnames are matched
+// exactly, ignoring case and ports, so subdomains must be listed too.
+func WithAllowedHosts(hosts ...string) func(c *client) {
+	return func(c *client) {
+		c.allowedHosts = map[string]bool{}
+		for _, host := range hosts {
+			c.allowedHosts[strings.ToLower(host)] = true
+		}
+	}
+}
+
+// allowedHostsTransport rejects requests to hosts that aren't allowed before
+// handing the others to next
+type allowedHostsTransport struct {
+	hosts map[string]bool
+	next  http.RoundTripper
+}
+
+func (t *allowedHostsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.hosts[strings.ToLower(req.URL.Hostname())] {
+		return nil, ErrHostNotAllowed{Host: req.URL.Hostname()}
+	}
+	return t.next.RoundTrip(req)
+}
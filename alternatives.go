@@ -0,0 +1,86 @@
+package pkggodev
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+
+	"golang.org/x/mod/module"
+)
+
+// maxQueryKeywords caps the synopsis keywords added to the name of a package
+// to search for its alternatives
+const maxQueryKeywords = 3
+
+// queryStopwords are words of synopses that say nothing about what a package
+// does
+var queryStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "by": true, "for": true, "from": true,
+	"go": true, "golang": true, "implements": true, "in": true, "is": true,
+	"it": true, "library": true, "of": true, "on": true, "or": true,
+	"package": true, "provides": true, "that": true, "the": true, "this": true,
+	"to": true, "with": true,
+}
+
+// unversionedPath strips the major version suffix of a path, like /v2 or
+// gopkg.in's .v2
+func unversionedPath(p string) string {
+	if prefix, _, ok := module.SplitPathVersion(p); ok {
+		return prefix
+	}
+	return p
+}
+
+// alternativesQuery derives a search query from a package's name and the
+// first keywords of its synopsis
+func alternativesQuery(pkg *Package) string {
+	name := strings.ToLower(path.Base(unversionedPath(pkg.Package)))
+	words := []string{name}
+	seen := map[string]bool{name: true}
+	for _, word := range strings.FieldsFunc(strings.ToLower(pkg.Synopsis), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(words) > maxQueryKeywords {
+			break
+		}
+		if len(word) < 3 || queryStopwords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+// Alternatives looks for packages doing the same as pkg, searching for its
+// name along with keywords of its synopsis. Results from pkg's own module,
+// in any major version, are left out and the rest is ranked by imported by
+// count.
+func (c *client) Alternatives(pkg string, limit int) ([]SearchResult, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("%w: limit must be positive, got %d", ErrInvalidRequest, limit)
+	}
+	described, err := c.DescribePackage(DescribePackageRequest{Package: pkg})
+	if err != nil {
+		return nil, fmt.Errorf("describing '%s': %w", pkg, err)
+	}
+
+	ownModules := map[string]bool{unversionedPath(inferModulePath(pkg)): true}
+	for _, modulePath := range described.MajorVersions {
+		ownModules[unversionedPath(modulePath)] = true
+	}
+	// some results will be left out, so ask for a page more than needed
+	results, err := c.Search(SearchRequest{Query: alternativesQuery(described), Limit: limit + 25})
+	if err != nil {
+		return nil, fmt.Errorf("searching alternatives to '%s': %w", pkg, err)
+	}
+	results = results.Filter(func(result SearchResult) bool {
+		return !ownModules[unversionedPath(result.ModulePath)]
+	})
+	results.SortBy("importedby", false)
+	if len(results.Results) > limit {
+		results.Results = results.Results[:limit]
+	}
+	return results.Results, nil
+}
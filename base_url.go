@@ -0,0 +1,33 @@
+package pkggodev
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidBaseURL is returned, wrapped with the details, by the methods
+// fetching pages of pkg.go.dev when the URL given to WithBaseURL is unusable
+var ErrInvalidBaseURL = errors.New("invalid base URL")
+
+// normalizeBaseURL checks that a base URL has a scheme and a host, and
+// returns it without trailing slashes so that paths can be appended. A path
+// prefix, like that of a mirror served under /pkgsite, is kept.
+func normalizeBaseURL(raw string) (string, error) {
+	trimmed := strings.TrimRight(strings.TrimSpace(raw), "/")
+	u, err := url.Parse(trimmed)
+	if err != nil {
+		return "", fmt.Errorf("%w: parsing '%s': %v", ErrInvalidBaseURL, raw, err)
+	}
+	switch {
+	case u.Scheme != "http" && u.Scheme != "https":
+		// "localhost:8080" parses with "localhost" as its scheme
+		return "", fmt.Errorf("%w: '%s' needs an http or https scheme", ErrInvalidBaseURL, raw)
+	case u.Host == "":
+		return "", fmt.Errorf("%w: '%s' has no host", ErrInvalidBaseURL, raw)
+	case u.RawQuery != "" || u.Fragment != "":
+		return "", fmt.Errorf("%w: '%s' has a query or fragment", ErrInvalidBaseURL, raw)
+	}
+	return trimmed, nil
+}
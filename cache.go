@@ -0,0 +1,218 @@
+package pkggodev
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultNegativeCacheTTL is how long not found answers are cached unless
+// changed with WithNegativeCacheTTL
+const defaultNegativeCacheTTL = time.Minute
+
+// WithCache caches pkg.go.dev's responses in memory for ttl. Once an entry is
+// stale it's revalidated with a conditional request when the server sent an
+// ETag or Last-Modified header, so unchanged pages aren't downloaded again.
+// Not found answers are cached too, for a shorter time set with
+// WithNegativeCacheTTL. Hits, misses and revalidations are counted in Metrics,
+// see also CacheStats and CacheEvict.
+func WithCache(ttl time.Duration) func(c *client) {
+	return func(c *client) {
+		c.cache = &responseCache{
+			ttl:     ttl,
+			entries: map[string]*cacheEntry{},
+			now:     time.Now,
+		}
+	}
+}
+
+// WithNegativeCacheTTL sets how long WithCache remembers not found answers,
+// one minute by default, so paths that don't exist cost a request only once
+// in a while. It's capped at the cache's ttl and zero disables it.
+func WithNegativeCacheTTL(ttl time.Duration) func(c *client) {
+	return func(c *client) {
+		c.negativeCacheTTL = ttl
+	}
+}
+
+// responseCache holds successful GET responses by URL, and not found ones
+// when negativeTTL is positive
+type responseCache struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	negativeTTL time.Duration
+	entries     map[string]*cacheEntry
+	now         func() time.Time // replaced in tests
+}
+
+type cacheEntry struct {
+	statusCode   int // http.StatusNotFound for negative entries
+	header       http.Header
+	body         []byte
+	etag         string
+	lastModified string
+	expires      time.Time
+}
+
+// response rebuilds the cached response as the answer to req
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.statusCode, http.StatusText(e.statusCode)),
+		StatusCode:    e.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// lookup returns the entry for key, if any, and whether it's still fresh
+func (rc *responseCache) lookup(key string) (*cacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return entry, rc.now().Before(entry.expires)
+}
+
+// store adds or replaces the entry for key, so a page fetched after a not
+// found answer evicts the negative entry
+func (rc *responseCache) store(key string, entry *cacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	ttl := rc.ttl
+	if entry.statusCode == http.StatusNotFound {
+		ttl = rc.negativeTTL
+	}
+	entry.expires = rc.now().Add(ttl)
+	rc.entries[key] = entry
+}
+
+// refresh extends the lifetime of an entry the server reported unchanged
+func (rc *responseCache) refresh(entry *cacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry.expires = rc.now().Add(rc.ttl)
+}
+
+// evict removes the entry for key, if any
+func (rc *responseCache) evict(key string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	delete(rc.entries, key)
+}
+
+// len returns the number of entries, stale ones included
+func (rc *responseCache) len() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.entries)
+}
+
+// CacheStats returns the cache's counters since the client was created or its
+// metrics last reset, and the number of cached responses. Responses the
+// server confirmed unchanged count as hits. Stale entries are kept for
+// revalidation, so they count in entries. All are zero without WithCache.
+func (c *client) CacheStats() (hits, misses, entries int) {
+	if c.cache == nil {
+		return 0, 0, 0
+	}
+	hits = int(c.metrics.cacheHits.Load() + c.metrics.cacheRevalidations.Load())
+	return hits, int(c.metrics.cacheMisses.Load()), c.cache.len()
+}
+
+// CacheEvict removes the cached response of a URL, like
+// https://pkg.go.dev/github.com/foo/bar?tab=versions, so that the next
+// request for it reaches the server. Negative entries are evicted too.
+func (c *client) CacheEvict(key string) {
+	if c.cache == nil {
+		return
+	}
+	c.cache.evict(key)
+}
+
+// freshKey marks the context of requests that skip the cache
+type freshKey struct{}
+
+// withFresh makes the requests made with ctx skip cached entries, negative
+// ones included. Their responses are still cached.
+func withFresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshKey{}, true)
+}
+
+func isFresh(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshKey{}).(bool)
+	return fresh
+}
+
+// cachingTransport answers GET requests from the cache, falling back to next
+type cachingTransport struct {
+	cache   *responseCache
+	metrics *clientMetrics
+	next    http.RoundTripper
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next.RoundTrip(req)
+	}
+	key := req.URL.String()
+	var entry *cacheEntry
+	var fresh bool
+	if !isFresh(req.Context()) {
+		entry, fresh = t.cache.lookup(key)
+	}
+	if fresh {
+		t.metrics.cacheHits.Add(1)
+		return entry.response(req), nil
+	}
+
+	if entry != nil && (entry.etag != "" || entry.lastModified != "") {
+		req = req.Clone(req.Context())
+		if entry.etag != "" {
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+		if entry.lastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.lastModified)
+		}
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		t.cache.refresh(entry)
+		t.metrics.cacheRevalidations.Add(1)
+		return entry.response(req), nil
+	}
+	t.metrics.cacheMisses.Add(1)
+	negative := resp.StatusCode == http.StatusNotFound && t.cache.negativeTTL > 0
+	if resp.StatusCode != http.StatusOK && !negative {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.cache.store(key, &cacheEntry{
+		statusCode:   resp.StatusCode,
+		header:       resp.Header.Clone(),
+		body:         body,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
@@ -1,27 +1,88 @@
 package pkggodev
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
-	"net/url"
-	"regexp"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/gocolly/colly/v2"
 	"github.com/projectdiscovery/useragent"
+	"golang.org/x/mod/semver"
 )
 
 type client struct {
 	httpClient *http.Client
 	baseURL    string
+	cookieJar  http.CookieJar
+	// pkgGoDevOnly restricts requests to the host of baseURL
+	pkgGoDevOnly bool
+	onError      func(err error, url string)
+	// maxPages caps how many pages paginating methods visit per call
+	maxPages  int
+	selectors Selectors
+	proxyURL  string
+	metrics   clientMetrics
+	// maxGraphNodes caps the packages in graphs built by ResolveDependents
+	maxGraphNodes int
+	// authorization is sent as the Authorization header to the host of baseURL
+	authorization string
+	// gitHosts are git hosts registered on top of builtinGitHosts
+	gitHosts map[string]GitHostType
+	cache    *responseCache
+	// pollInterval is the delay between polls of SubscribeToVulnerabilities
+	pollInterval time.Duration
+	// allowedHosts restricts requests to these hostnames when not nil
+	allowedHosts map[string]bool
+	// torProxy is the SOCKS5 proxy every request goes through when not nil
+	torProxy *url.URL
+	// timeout overrides the request timeout when positive
+	timeout time.Duration
+	// negativeCacheTTL is applied to cache once the options are
+	negativeCacheTTL time.Duration
+	// strictVersions fails instead of warning about malformed versions
+	strictVersions bool
+	// concurrency caps the repositories PackagesByStar fetches at once
+	concurrency int
+	// baseURLErr is returned by the methods fetching pages when the base
+	// URL is unusable
+	baseURLErr error
+	sumDBURL   string
+	// location is the time zone of normalized dates, UTC unless changed
+	// with WithTimezone
+	location *time.Location
+	clock    func() time.Time // replaced in tests
 }
 
 var ErrNotFound = errors.New("not found on pkg.go.dev")
 
+// ErrInterstitialBlocked is returned when a repository host answers with a
+// "checking your browser" style page instead of the requested content
+var ErrInterstitialBlocked = errors.New("blocked by browser check interstitial")
+
+// ErrExternalFetchDisabled is returned by methods that need to reach hosts
+// other than pkg.go.dev when the client was created with WithPkgGoDevOnly
+var ErrExternalFetchDisabled = errors.New("fetching from hosts other than pkg.go.dev is disabled")
+
+// Errors returned by Ping, wrapped with the details of the failure
+var (
+	ErrUnreachable       = errors.New("base URL unreachable")
+	ErrUnexpectedStatus  = errors.New("unexpected HTTP status")
+	ErrUnexpectedContent = errors.New("unexpected page content")
+)
+
 type ErrorList struct {
 	Errs []error
 }
@@ -30,19 +91,55 @@ func (e *ErrorList) Error() string {
 	return fmt.Sprintf("errors: %v", e.Errs)
 }
 
+func (e *ErrorList) Unwrap() []error {
+	return e.Errs
+}
+
+// defaultMaxPages is the number of pages paginating methods visit per call
+// unless changed with WithMaxPages
+const defaultMaxPages = 10
+
 func New(options ...func(c *client)) *client {
 	c := &client{
-		baseURL: "https://pkg.go.dev",
+		baseURL:   "https://pkg.go.dev",
+		maxPages:  defaultMaxPages,
+		selectors: DefaultSelectors(),
+		proxyURL:  defaultProxyURL,
+		sumDBURL:  defaultSumDBURL,
+		location:  time.UTC,
+		clock:     time.Now,
+
+		maxGraphNodes:    defaultMaxGraphNodes,
+		pollInterval:     defaultPollInterval,
+		negativeCacheTTL: defaultNegativeCacheTTL,
 	}
 	for _, opt := range options {
 		opt(c)
 	}
+	if c.cache != nil {
+		c.cache.negativeTTL = min(c.negativeCacheTTL, c.cache.ttl)
+	}
+	if c.cookieJar == nil {
+		if c.httpClient != nil && c.httpClient.Jar != nil {
+			c.cookieJar = c.httpClient.Jar
+		} else {
+			// cookiejar.New only fails when given bad options
+			c.cookieJar, _ = cookiejar.New(nil)
+		}
+	}
 	return c
 }
 
+// WithBaseURL points the client at a pkg.go.dev mirror, like
+// "http://localhost:8080" or "https://example.com/pkgsite". Trailing slashes
+// are dropped. A URL without an http or https scheme or without a host makes
+// every method fetching pages fail with ErrInvalidBaseURL.
 func WithBaseURL(url string) func(c *client) {
 	return func(c *client) {
-		c.baseURL = url
+		c.baseURL, c.baseURLErr = normalizeBaseURL(url)
+		if c.baseURLErr != nil {
+			c.baseURL = url
+		}
 	}
 }
 
@@ -52,11 +149,165 @@ func WithHTTPClient(httpClient *http.Client) func(c *client) {
 	}
 }
 
+// WithCookieJar sets the cookie jar shared by every request the client makes,
+// so cookies set by one call (e.g. consent pages) are sent by the next
+func WithCookieJar(jar http.CookieJar) func(c *client) {
+	return func(c *client) {
+		c.cookieJar = jar
+	}
+}
+
+// WithPkgGoDevOnly prevents the client from making requests to any host other
+// than the one in its base URL. Methods that need other hosts return
+// ErrExternalFetchDisabled without making any request, except Sprinkle which
+// still sets the synopsis from MetaDescription when there's one.
+func WithPkgGoDevOnly() func(c *client) {
+	return func(c *client) {
+		c.pkgGoDevOnly = true
+	}
+}
+
+// WithOnError registers a callback invoked with every failed request the
+// client makes, e.g. to report errors to an observability platform
+func WithOnError(fn func(err error, url string)) func(c *client) {
+	return func(c *client) {
+		c.onError = fn
+	}
+}
+
+// WithBasicAuth authenticates requests to the host of the base URL, e.g. a
+// private pkg.go.dev-like frontend. Credentials are never sent to other hosts,
+// including when redirected to one.
+func WithBasicAuth(user, pass string) func(c *client) {
+	return func(c *client) {
+		c.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+	}
+}
+
+// WithBearerToken is like WithBasicAuth, for token authentication
+func WithBearerToken(token string) func(c *client) {
+	return func(c *client) {
+		c.authorization = "Bearer " + token
+	}
+}
+
+// defaultRequestTimeout is colly's request timeout, also given to the HTTP
+// clients of newHTTPClient
+const defaultRequestTimeout = 10 * time.Second
+
+// WithTimeout sets how long a request may take, including reading the
+// response, instead of the default of 10 seconds. Values below 1 are ignored.
+func WithTimeout(d time.Duration) func(c *client) {
+	return func(c *client) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithMaxPages caps how many result pages paginating methods like Search visit
+// in a single call, regardless of the requested Limit. It's the default for
+// SearchRequest.MaxPages. Values below 1 are ignored.
+func WithMaxPages(n int) func(c *client) {
+	return func(c *client) {
+		if n > 0 {
+			c.maxPages = n
+		}
+	}
+}
+
+// transport returns the transport of the client's HTTP client, wrapped by the
+// options applying to every request: Tor, the cache and the allowed hosts
+func (c *client) transport() http.RoundTripper {
+	transport := http.DefaultTransport
+	if c.httpClient != nil && c.httpClient.Transport != nil {
+		transport = c.httpClient.Transport
+	}
+	if c.torProxy != nil {
+		transport = torTransport(transport, c.torProxy)
+	}
+	if c.cache != nil {
+		transport = &cachingTransport{cache: c.cache, metrics: &c.metrics, next: transport}
+	}
+	if c.allowedHosts != nil {
+		transport = &allowedHostsTransport{hosts: c.allowedHosts, next: transport}
+	}
+	return transport
+}
+
+// newHTTPClient returns an HTTP client configured like the collectors of
+// newCollector, for requests colly doesn't fit, like downloading files
+func (c *client) newHTTPClient() *http.Client {
+	httpClient := &http.Client{Timeout: defaultRequestTimeout}
+	if c.httpClient != nil {
+		*httpClient = *c.httpClient
+	}
+	httpClient.Transport = c.transport()
+	httpClient.Jar = c.cookieJar
+	if c.timeout > 0 {
+		httpClient.Timeout = c.timeout
+	}
+	if c.pkgGoDevOnly {
+		next := httpClient.CheckRedirect
+		httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if !c.isBaseHost(req.URL) {
+				return ErrExternalFetchDisabled
+			}
+			if next != nil {
+				return next(req, via)
+			}
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return nil
+		}
+	}
+	return httpClient
+}
+
+// isBaseHost reports whether u is on the host of the base URL
+func (c *client) isBaseHost(u *url.URL) bool {
+	base, err := url.Parse(c.baseURL)
+	return err == nil && strings.EqualFold(u.Hostname(), base.Hostname())
+}
+
 func (c *client) newCollector() *colly.Collector {
 	col := colly.NewCollector()
 	if c.httpClient != nil {
-		col.SetClient(c.httpClient)
+		// copy so that setting the jar below doesn't mutate the caller's client
+		httpClient := *c.httpClient
+		col.SetClient(&httpClient)
+	}
+	if c.cookieJar != nil {
+		col.SetCookieJar(c.cookieJar)
+	}
+	if c.timeout > 0 {
+		col.SetRequestTimeout(c.timeout)
+	}
+	if c.cache != nil || c.allowedHosts != nil || c.torProxy != nil {
+		col.WithTransport(c.transport())
 	}
+	if c.pkgGoDevOnly {
+		if u, err := url.Parse(c.baseURL); err == nil {
+			col.AllowedDomains = []string{u.Hostname()}
+		}
+	}
+	if c.authorization != "" {
+		if u, err := url.Parse(c.baseURL); err == nil {
+			// colly drops the header when a redirect changes host
+			col.OnRequest(func(r *colly.Request) {
+				if r.URL.Host == u.Host {
+					r.Headers.Set("Authorization", c.authorization)
+				}
+			})
+		}
+	}
+	if c.onError != nil {
+		col.OnError(func(r *colly.Response, e error) {
+			c.onError(e, r.Request.URL.String())
+		})
+	}
+	c.trackMetrics(col)
 
 	filters := []useragent.Filter{
 		useragent.Chrome,
@@ -70,22 +321,129 @@ func (c *client) newCollector() *colly.Collector {
 	return col
 }
 
+// splitPagePath turns a package path as pasted by users into the path to
+// query and the version it names, if any. A leading pkg.go.dev or base URL,
+// the query string, the fragment and surrounding slashes are dropped, so
+// "https://pkg.go.dev/example.com/mod@v1.2.0/pkg?tab=doc#Section" gives
+// "example.com/mod/pkg" and "v1.2.0".
+func (c *client) splitPagePath(input string) (string, string) {
+	p := strings.TrimSpace(input)
+	for _, prefix := range []string{c.baseURL, "https://pkg.go.dev", "http://pkg.go.dev", "pkg.go.dev"} {
+		if rest, ok := strings.CutPrefix(p, prefix+"/"); ok {
+			p = rest
+			break
+		}
+	}
+	if i := strings.IndexAny(p, "?#"); i >= 0 {
+		p = p[:i]
+	}
+	p = strings.Trim(p, "/")
+	// pkg.go.dev puts the version after the module path: mod@version/pkg
+	at := strings.Index(p, "@")
+	if at < 0 {
+		return p, ""
+	}
+	version, subpath, _ := strings.Cut(p[at+1:], "/")
+	if subpath != "" {
+		return p[:at] + "/" + subpath, version
+	}
+	return p[:at], version
+}
+
+// pageURL returns the pkg.go.dev URL of a package or module path. Unlike in
+// module proxy requests, paths aren't case-encoded: pkg.go.dev takes them
+// as is, only escaping what isn't allowed in a URL path.
+func (c *client) pageURL(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	for i, elem := range elems {
+		elems[i] = url.PathEscape(elem)
+	}
+	return c.baseURL + "/" + strings.Join(elems, "/")
+}
+
 type ImportedByRequest struct {
-	Package string
+	Package      string // import path, or a pkg.go.dev URL of the package
+	CollectStats bool
+	// Fresh skips WithCache's entries, including remembered not found answers
+	Fresh bool
+	// Context cancels the request, defaulting to context.Background
+	Context context.Context
 }
 
 type ImportedBy struct {
 	Package    string
 	ImportedBy []string
+	// Groups holds the importers grouped by module, in page order. Counting
+	// groups rather than packages avoids over-counting large modules.
+	Groups []ImporterGroup
+	Stats  *Stats
+	// Response describes the response of the importedby tab
+	Response ResponseMeta
+}
+
+// ImporterGroup lists the importing packages of a module. pkg.go.dev only
+// groups modules with several importers, so Module is the package itself for
+// importers listed on their own.
+type ImporterGroup struct {
+	Module   string
+	Packages []string
+}
+
+// Importer is a package importing another, yielded by ImportedByIterator
+type Importer struct {
+	Package string
+	Module  string // module of Package as grouped on the page
 }
 
 func (c *client) ImportedBy(req ImportedByRequest) (*ImportedBy, error) {
+	return c.scrapeImportedBy(req, nil)
+}
+
+// scrapeImportedBy fetches the importedby tab, passing each importer to emit
+// as it's parsed when emit isn't nil. Parsing stops once emit returns false.
+func (c *client) scrapeImportedBy(req ImportedByRequest, emit func(Importer) bool) (*ImportedBy, error) {
+	if c.baseURLErr != nil {
+		return nil, c.baseURLErr
+	}
 	col := c.newCollector()
+	if req.Context != nil {
+		col.Context = req.Context
+	}
+	if req.Fresh {
+		col.Context = withFresh(col.Context)
+	}
+	req.Package, _ = c.splitPagePath(req.Package)
 	importedBy := &ImportedBy{Package: req.Package}
 	var err error
+	var stats *Stats
+	if req.CollectStats {
+		stats = trackStats(col)
+	}
+	trackResponse(col, &importedBy.Response, c.now)
 
-	col.OnHTML(".u-breakWord", func(e *colly.HTMLElement) {
-		importedBy.ImportedBy = append(importedBy.ImportedBy, strings.TrimSpace(e.Text))
+	stopped := false
+	col.OnHTML(c.selectors.ImportedBy, func(e *colly.HTMLElement) {
+		if stopped {
+			return
+		}
+		pkg := strings.TrimSpace(e.Text)
+		importedBy.ImportedBy = append(importedBy.ImportedBy, pkg)
+
+		module := pkg
+		if group := e.DOM.Closest(c.selectors.ImportedByGroup); group.Length() > 0 {
+			summary := group.Find(c.selectors.ImportedByGroupModule).First().Clone()
+			summary.Children().Remove() // drop the importer count
+			module = strings.TrimSpace(summary.Text())
+		}
+		if emit != nil {
+			stopped = !emit(Importer{Package: pkg, Module: module})
+		}
+		groups := importedBy.Groups
+		if len(groups) > 0 && groups[len(groups)-1].Module == module {
+			groups[len(groups)-1].Packages = append(groups[len(groups)-1].Packages, pkg)
+			return
+		}
+		importedBy.Groups = append(groups, ImporterGroup{Module: module, Packages: []string{pkg}})
 	})
 	col.OnError(func(r *colly.Response, e error) {
 		if r.StatusCode == 404 {
@@ -94,15 +452,130 @@ func (c *client) ImportedBy(req ImportedByRequest) (*ImportedBy, error) {
 		}
 		err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
 	})
-	col.Visit(fmt.Sprintf("%s/%s?tab=importedby", c.baseURL, req.Package))
+	col.Visit(c.pageURL(req.Package) + "?tab=importedby")
 	if err != nil {
 		return nil, err
 	}
+	if stats != nil {
+		importedBy.Stats = stats.finish()
+	}
 	return importedBy, nil
 }
 
+// PackageExists reports whether pkg has a page on pkg.go.dev. It only makes a
+// HEAD request, so it's much cheaper than DescribePackage. Failures are
+// reported like by Exists.
+func (c *client) PackageExists(ctx context.Context, pkg string) (bool, error) {
+	if c.baseURLErr != nil {
+		return false, c.baseURLErr
+	}
+	exists, _, err := c.existsRequest(ctx, http.MethodHead, c.pageURL(pkg))
+	return exists, err
+}
+
+// Exists reports whether path has a page on pkg.go.dev without downloading
+// it. It makes a HEAD request, falling back to a GET of the page for servers
+// that don't allow HEAD (the badge SVG can't tell, as it's served for any
+// path). (false, nil) is a definitive answer, failures to reach the server
+// are reported wrapping ErrUnreachable.
+func (c *client) Exists(path string) (bool, error) {
+	if c.baseURLErr != nil {
+		return false, c.baseURLErr
+	}
+	exists, status, err := c.existsRequest(context.Background(), http.MethodHead, c.pageURL(path))
+	if status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented {
+		exists, _, err = c.existsRequest(context.Background(), http.MethodGet, c.pageURL(path))
+	}
+	return exists, err
+}
+
+// existsRequest makes a request whose status tells whether a page exists
+func (c *client) existsRequest(ctx context.Context, method, url string) (bool, int, error) {
+	col := c.newCollector()
+	col.Context = ctx
+	var statusCode int
+
+	col.OnResponse(func(r *colly.Response) {
+		statusCode = r.StatusCode
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		statusCode = r.StatusCode
+	})
+	err := col.Request(method, url, nil, nil, nil)
+
+	switch {
+	case statusCode == http.StatusOK:
+		return true, statusCode, nil
+	case statusCode == http.StatusNotFound:
+		return false, statusCode, nil
+	case statusCode == 0 && err != nil:
+		return false, 0, fmt.Errorf("%w: %s: %v", ErrUnreachable, url, err)
+	default:
+		return false, statusCode, fmt.Errorf("%w: %s answered %d %s", ErrUnexpectedStatus, url, statusCode, http.StatusText(statusCode))
+	}
+}
+
+// pingTimeout bounds Ping regardless of the HTTP client's own timeout
+const pingTimeout = 5 * time.Second
+
+// Ping checks that the base URL is reachable and serves a page that looks like
+// pkg.go.dev, by fetching the homepage and looking for the search form. The
+// returned error wraps ErrUnreachable, ErrUnexpectedStatus or
+// ErrUnexpectedContent.
+func (c *client) Ping(ctx context.Context) error {
+	if c.baseURLErr != nil {
+		return c.baseURLErr
+	}
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	col := c.newCollector()
+	col.Context = ctx
+	url := c.baseURL + "/"
+	var statusCode int
+	var hasSearchForm bool
+
+	col.OnHTML("form[action='/search']", func(e *colly.HTMLElement) {
+		hasSearchForm = true
+	})
+	col.OnResponse(func(r *colly.Response) {
+		statusCode = r.StatusCode
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		statusCode = r.StatusCode
+	})
+	err := col.Visit(url)
+
+	switch {
+	case statusCode == 0 && err != nil:
+		return fmt.Errorf("%w: %s: %w", ErrUnreachable, url, err)
+	case statusCode != http.StatusOK:
+		return fmt.Errorf("%w: %s returned %d", ErrUnexpectedStatus, url, statusCode)
+	case !hasSearchForm:
+		return fmt.Errorf("%w: no search form found at %s", ErrUnexpectedContent, url)
+	}
+	return nil
+}
+
 type DescribePackageRequest struct {
+	// Package is an import path, or a pkg.go.dev URL of the package whose
+	// version is used when Version is empty
 	Package string
+	// Version describes a specific version instead of the latest one. Besides
+	// versions it accepts branch names like "master", which are resolved to the
+	// matching pseudo-version.
+	Version string
+	// Platform selects the documentation of a GOOS/GOARCH pair like
+	// "windows/amd64", or of a GOOS alone, for packages with build constraints
+	Platform     string
+	CollectStats bool
+	// Fresh skips WithCache's entries, including remembered not found answers
+	Fresh bool
+	// MinimalOK returns the package even when the UnitMeta checklist or the
+	// title badges fail to parse, reporting the failures in Warnings, as long
+	// as the minimal set of unit header fields parsed: Version, Published and
+	// the license element, which may say no license was detected
+	MinimalOK bool
 }
 
 type Image struct {
@@ -112,65 +585,423 @@ type Image struct {
 
 type Package struct {
 	Package                   string
+	RequestedVersion          string // Version from the request, e.g. a branch name
 	IsModule                  bool
 	IsPackage                 bool
 	Version                   string
+	ModuleVersion             string   // major version of the module, e.g. "v2"
+	IsLatest                  bool     // false when pkg.go.dev links to a newer version
+	LatestVersion             string   // empty when outdated and the link doesn't name the version
+	MajorVersions             []string // module paths of every major version, like ".../v2"
+	EffectivePlatform         string   // GOOS/GOARCH of the docs, empty when the package has no build constraints
 	Published                 string
 	License                   string
+	LicenseDetailsURL         string // link to the license on the licenses tab, empty when none was detected
 	HasValidGoModFile         bool
 	HasRedistributableLicense bool
 	HasTaggedVersion          bool
 	HasStableVersion          bool
 	Repository                string
+	SourceCodeURL             string // directory holding the package's source on its repository host
 	Synopsis                  string
-	Images                    []Image
+	SynopsisSource            SynopsisSource
+	MetaDescription           string // summary from the page's description meta tags
+	DocumentedSymbolCount     int    // sum of the counts below
+	FunctionCount             int
+	TypeCount                 int
+	MethodCount               int
+	ConstCount                int
+	VarCount                  int
+	Images                    ImageSet
+	CoveragePercent           float64 // from a coverage badge in the README, see HasCoverageData
+	IssueCount                int     // open issues on GitHub or GitLab, set by Sprinkle
+	IsArchived                bool    // repository archived, from a pkg.go.dev banner or set by Sprinkle
+	ContributorCount          int     // contributors on GitHub or GitLab, set by Sprinkle with SprinkleContributors
+	Stars                     int     // stars on GitHub or GitLab, set by Sprinkle
+	ForkCount                 int     // forks on GitHub, set by Sprinkle unless SprinkleNoNetworkCounts
+	WatcherCount              int     // watchers on GitHub, set by Sprinkle unless SprinkleNoNetworkCounts
+	Stats                     *Stats
+	Truncated                 bool     // some sections were collapsed or cut short on pkg.go.dev
+	TruncatedSections         []string // "documentation" or "readme"
+	// Warnings lists the anomalies that didn't prevent describing the
+	// package, as *FieldError holding the text that couldn't be parsed
+	Warnings []error
+	// Response describes the response of the unit page
+	Response ResponseMeta
+	// Maintainers lists the authors or maintainers named in the page's
+	// metadata, or else the organization owning the module path, like
+	// "spf13" for github.com/spf13/cobra or "golang.org" for golang.org/x/mod
+	Maintainers []string
+	// ImportedByCount is the count of importers shown in the header
+	ImportedByCount int
+	// Deprecated is set when the module carries pkg.go.dev's deprecated badge
+	Deprecated bool
+	// SupersededBy is the path of the module replacing this one, like
+	// github.com/golang-jwt/jwt for github.com/dgrijalva/jwt-go, from the
+	// banner shown for moved modules or a go.mod deprecation message naming
+	// it. It may be set without Deprecated, and Deprecated without it.
+	SupersededBy string
+
+	hasCoverage bool
+}
+
+// HasCoverageData reports whether CoveragePercent was read from the page, to
+// tell 0% coverage apart from no coverage data
+func (p *Package) HasCoverageData() bool {
+	return p.hasCoverage
+}
+
+// coverageBadgeHosts are services whose README badges report test coverage
+var coverageBadgeHosts = []string{"codecov.io", "coveralls.io", "codeclimate.com"}
+
+// coveragePercent matches a percentage like "85%" or "85.3%"
+var coveragePercent = regexp.MustCompile(`(\d+(?:\.\d+)?)%`)
+
+// parseCoverageBadge reads the percentage off a coverage badge. Only badges
+// carrying it in their alt text or URL (like shields.io's) can be read, the
+// percentage of image-only badges isn't known.
+func parseCoverageBadge(alt, src string) (float64, bool) {
+	isCoverage := strings.Contains(strings.ToLower(alt), "coverage") || strings.Contains(strings.ToLower(src), "coverage")
+	for _, host := range coverageBadgeHosts {
+		isCoverage = isCoverage || strings.Contains(src, host)
+	}
+	if !isCoverage {
+		return 0, false
+	}
+	decoded, err := url.QueryUnescape(src)
+	if err != nil {
+		decoded = src
+	}
+	for _, text := range []string{alt, decoded} {
+		if m := coveragePercent.FindStringSubmatch(text); m != nil {
+			percent, err := strconv.ParseFloat(m[1], 64)
+			if err == nil && percent <= 100 {
+				return percent, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// majorPathComponent matches the major version suffix path component of v2+ modules
+var majorPathComponent = regexp.MustCompile(`^v([2-9]|[1-9][0-9]+)$`)
+
+// PublishedTime parses Published, returning ErrNilDate when it's empty
+func (p *Package) PublishedTime() (time.Time, error) {
+	t, err := parseDate(p.Published)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing published date of '%s': %w", p.Package, err)
+	}
+	return t, nil
+}
+
+// IsModuleV2Plus reports whether the package path has a /v2 or higher major
+// version component, as packages in v2+ modules do, or a gopkg.in .v2 or
+// higher suffix
+func (p *Package) IsModuleV2Plus() bool {
+	if major := gopkgInMajor(p.Package); major != "" {
+		return major != "v0" && major != "v1"
+	}
+	for _, component := range strings.Split(p.Package, "/") {
+		if majorPathComponent.MatchString(component) {
+			return true
+		}
+	}
+	return false
+}
+
+// repoRootHosts are hosts whose repository roots are host/owner/repo
+var repoRootHosts = []string{"github.com", "gitlab.com", "codeberg.org", "git.sr.ht", "bitbucket.org"}
+
+// gopkgInVersion matches the version suffix of gopkg.in paths
+var gopkgInVersion = regexp.MustCompile(`\.v[0-9]+$`)
+
+// inferModulePath guesses the module path of a package. A major version
+// suffix always ends the module path, for other packages the repository
+// root is assumed on well-known hosts and the package path elsewhere.
+func inferModulePath(importPath string) string {
+	components := strings.Split(importPath, "/")
+	for i, component := range components {
+		if majorPathComponent.MatchString(component) {
+			return strings.Join(components[:i+1], "/")
+		}
+	}
+	if components[0] == "gopkg.in" {
+		// gopkg.in/yaml.v3 or gopkg.in/user/pkg.v3
+		for i, component := range components {
+			if gopkgInVersion.MatchString(component) {
+				return strings.Join(components[:i+1], "/")
+			}
+		}
+	}
+	for _, host := range repoRootHosts {
+		if components[0] == host && len(components) >= 3 {
+			return strings.Join(components[:3], "/")
+		}
+	}
+	return importPath
+}
+
+// latestLinkVersion matches the version in the link to the latest version
+var latestLinkVersion = regexp.MustCompile(`@(v[^/?#]+)`)
+
+// unitHeaderVersion extracts the version from the unit header's version element
+func unitHeaderVersion(e *colly.HTMLElement) string {
+	versionStr := e.DOM.Children().First().Text()
+	return strings.TrimSpace(strings.TrimPrefix(versionStr, "Version: "))
+}
+
+// unitHeaderLicense extracts the license list from the unit header's license
+// element, which is empty when pkg.go.dev detected no license
+func unitHeaderLicense(e *colly.HTMLElement) string {
+	licenseStr := strings.TrimSpace(e.DOM.Children().First().Text())
+	// pkg.go.dev renders "None detected" instead of a link when it finds no license
+	if licenseStr == "None detected" {
+		return ""
+	}
+	return licenseStr
+}
+
+// unitMetaChecks reports which items of the UnitMeta checklist are checked:
+// valid go.mod file, redistributable license, tagged version, stable version
+func unitMetaChecks(e *colly.HTMLElement, sel Selectors) [4]bool {
+	var checks [4]bool
+	e.DOM.Find("li").Each(func(i int, s *goquery.Selection) {
+		if i < len(checks) {
+			checks[i] = s.Find(sel.UnitMetaChecked).Length() > 0
+		}
+	})
+	return checks
+}
+
+// errUnknownChecklistRow is warned about for UnitMeta rows past the known ones
+var errUnknownChecklistRow = errors.New("unknown checklist row")
+
+// errIncompleteChecklist is reported when UnitMeta lacks some of the known rows
+var errIncompleteChecklist = errors.New("checklist has fewer rows than expected")
+
+// unknownUnitMetaRows returns the text of the UnitMeta checklist rows that
+// unitMetaChecks doesn't know about
+func unknownUnitMetaRows(e *colly.HTMLElement) []string {
+	var rows []string
+	items := e.DOM.Find("li")
+	if items.Length() <= 4 {
+		return nil
+	}
+	items.Slice(4, goquery.ToEnd).Each(func(_ int, s *goquery.Selection) {
+		rows = append(rows, strings.Join(strings.Fields(s.Text()), " "))
+	})
+	return rows
+}
+
+// isVersionQuery reports whether v is a query like a branch name rather than a
+// version. "latest" is left to pkg.go.dev, which resolves it by default.
+func isVersionQuery(v string) bool {
+	return v != "" && v != "latest" && !semver.IsValid(v)
+}
+
+// SynopsisSource records where a Package's Synopsis came from. Sources are
+// ordered by trustworthiness, so a greater value is a better source.
+type SynopsisSource int
+
+const (
+	SynopsisSourceUnknown SynopsisSource = iota
+	SynopsisSourceRepository
+	SynopsisSourceMetaTag
+	SynopsisSourceSearchSnippet
+	SynopsisSourceDocOverview
+)
+
+func (s SynopsisSource) String() string {
+	switch s {
+	case SynopsisSourceRepository:
+		return "Repository"
+	case SynopsisSourceMetaTag:
+		return "MetaTag"
+	case SynopsisSourceSearchSnippet:
+		return "SearchSnippet"
+	case SynopsisSourceDocOverview:
+		return "DocOverview"
+	default:
+		return "Unknown"
+	}
+}
+
+// setSynopsis sets the synopsis unless the current one comes from a more
+// trustworthy source and force is false
+func (p *Package) setSynopsis(synopsis string, source SynopsisSource, force bool) {
+	if !force && source < p.SynopsisSource {
+		return
+	}
+	p.Synopsis = synopsis
+	p.SynopsisSource = source
 }
 
 func (c *client) DescribePackage(req DescribePackageRequest) (*Package, error) {
+	return c.describePackage(req, nil)
+}
+
+// describePackage describes a package like DescribePackage, passing the
+// collector of the unit page to setup when not nil so that callers can
+// scrape more from it
+func (c *client) describePackage(req DescribePackageRequest, setup func(col *colly.Collector)) (*Package, error) {
+	if c.baseURLErr != nil {
+		return nil, c.baseURLErr
+	}
 	col := c.newCollector()
-	p := &Package{Package: req.Package}
+	if setup != nil {
+		setup(col)
+	}
+	if req.Fresh {
+		col.Context = withFresh(col.Context)
+	}
+	sel := c.selectors
+	var pathVersion string
+	req.Package, pathVersion = c.splitPagePath(req.Package)
+	if req.Version == "" {
+		req.Version = pathVersion
+	}
+	p := &Package{Package: req.Package, RequestedVersion: req.Version}
 	errs := &ErrorList{}
+	var stats *Stats
+	if req.CollectStats {
+		stats = trackStats(col)
+	}
+	trackResponse(col, &p.Response, c.now)
+
+	unitURL := c.pageURL(req.Package)
+	var resolvedVersion string
+	if req.Version != "" {
+		version := req.Version
+		if isVersionQuery(version) {
+			// pkg.go.dev abbreviates pseudo-versions in its header, so resolve
+			// the full one from the proxy, falling back to pkg.go.dev's own
+			// resolution of the @branch URL form
+			if v, err := c.resolveRef(col.Context, req.Package, version); err == nil {
+				resolvedVersion, version = v, v
+			}
+		}
+		unitURL += "@" + version
+	}
+	if req.Platform != "" {
+		goos, goarch, _ := strings.Cut(req.Platform, "/")
+		query := url.Values{"GOOS": {goos}}
+		if goarch != "" {
+			query.Set("GOARCH", goarch)
+		}
+		unitURL += "?" + query.Encode()
+	}
 
-	col.OnHTML("[data-test-id=UnitHeader-version]", func(e *colly.HTMLElement) {
-		versionStr := e.DOM.Children().First().Text()
-		version := strings.TrimSpace(strings.TrimPrefix(versionStr, "Version: "))
-		p.Version = version
-	})
-	col.OnHTML("[data-test-id=UnitHeader-licenses]", func(e *colly.HTMLElement) {
-		licenseStr := e.DOM.Children().First().Text()
-		p.License = strings.TrimSpace(licenseStr)
-	})
-	col.OnHTML(".UnitMeta", func(e *colly.HTMLElement) {
-		lis := e.DOM.Find("li")
-		lis.Each(func(i int, s *goquery.Selection) {
-			checked := s.Find("img[alt=checked]").Length() > 0
-			switch i {
-			case 0:
-				p.HasValidGoModFile = checked
-			case 1:
-				p.HasRedistributableLicense = checked
-			case 2:
-				p.HasTaggedVersion = checked
-			case 3:
-				p.HasStableVersion = checked
+	col.OnHTML(sel.UnitVersion, func(e *colly.HTMLElement) {
+		p.Version = unitHeaderVersion(e)
+		if err := c.reportVersion(p.Version, &p.Warnings); err != nil {
+			errs.Errs = append(errs.Errs, err)
+		}
+	})
+	var outdated bool
+	col.OnHTML(sel.UnitLatestBanner, func(e *colly.HTMLElement) {
+		text := strings.ToLower(strings.TrimSpace(e.Text))
+		if !strings.Contains(text, "latest") || text == "latest" {
+			return
+		}
+		// "Go to latest" or "There is a newer version" style banner
+		outdated = true
+		href := e.Attr("href")
+		if href == "" {
+			href = e.DOM.Find("a[href]").AttrOr("href", "")
+		}
+		if m := latestLinkVersion.FindStringSubmatch(href); m != nil {
+			p.LatestVersion = m[1]
+		}
+	})
+	col.OnHTML(sel.DocPlatform, func(e *colly.HTMLElement) {
+		platform := e.Attr("value")
+		if platform == "" {
+			platform = strings.TrimSpace(e.Text)
+		}
+		p.EffectivePlatform = platform
+	})
+	col.OnHTML(sel.UnitMajorVersions, func(e *colly.HTMLElement) {
+		link, err := url.Parse(e.Attr("href"))
+		if err != nil {
+			return
+		}
+		modulePath := directoryVersionPattern.ReplaceAllString(strings.Trim(link.Path, "/"), "")
+		for _, known := range p.MajorVersions {
+			if known == modulePath {
+				return
 			}
-		})
+		}
+		p.MajorVersions = append(p.MajorVersions, modulePath)
 	})
-	col.OnHTML(".UnitMeta-repo", func(e *colly.HTMLElement) {
+	var hasLicense bool
+	col.OnHTML(sel.UnitLicenses, func(e *colly.HTMLElement) {
+		hasLicense = true
+		p.License = unitHeaderLicense(e)
+		if href, ok := e.DOM.Find("a[href]").First().Attr("href"); ok {
+			p.LicenseDetailsURL = resolveURL(e.Request.URL, href)
+		}
+	})
+	// sectionErrs are parse failures outside the unit header, which
+	// MinimalOK turns into warnings
+	var sectionErrs []error
+	col.OnHTML(sel.UnitMeta, func(e *colly.HTMLElement) {
+		checks := unitMetaChecks(e, sel)
+		if rows := e.DOM.Find("li").Length(); rows < len(checks) {
+			sectionErrs = append(sectionErrs, &FieldError{
+				Field: "UnitMeta",
+				Raw:   strings.Join(strings.Fields(e.Text), " "),
+				Err:   fmt.Errorf("%w: %d of %d", errIncompleteChecklist, rows, len(checks)),
+			})
+		}
+		p.HasValidGoModFile = checks[0]
+		p.HasRedistributableLicense = checks[1]
+		p.HasTaggedVersion = checks[2]
+		p.HasStableVersion = checks[3]
+		for _, row := range unknownUnitMetaRows(e) {
+			p.Warnings = append(p.Warnings, &FieldError{Field: "UnitMeta", Raw: row, Err: errUnknownChecklistRow})
+		}
+	})
+	col.OnHTML(sel.UnitRepo, func(e *colly.HTMLElement) {
 		text := e.DOM.Children().First().Text()
 		p.Repository = strings.TrimSpace(strings.Trim(text, "\\n"))
 	})
-	col.OnHTML("[data-test-id=UnitHeader-commitTime]", func(e *colly.HTMLElement) {
+	markArchived := func(e *colly.HTMLElement) {
+		if strings.Contains(strings.ToLower(e.Text), "archived") {
+			p.IsArchived = true
+		}
+	}
+	col.OnHTML(sel.UnitBadge, markArchived)
+	col.OnHTML(sel.UnitBanner, markArchived)
+	col.OnHTML(sel.UnitBadge, func(e *colly.HTMLElement) {
+		if strings.EqualFold(strings.TrimSpace(e.Text), "deprecated") {
+			p.Deprecated = true
+		}
+	})
+	col.OnHTML(sel.UnitBanner, func(e *colly.HTMLElement) {
+		if p.SupersededBy == "" {
+			p.SupersededBy = bannerSuccessor(e, p.Package)
+		}
+	})
+	col.OnHTML(sel.UnitImportedBy, func(e *colly.HTMLElement) {
+		count, err := parseCount(e.Text)
+		if err != nil {
+			p.Warnings = append(p.Warnings, &FieldError{Field: "ImportedByCount", Raw: strings.TrimSpace(e.Text), Err: err})
+			return
+		}
+		p.ImportedByCount = count
+	})
+	col.OnHTML(sel.UnitCommitTime, func(e *colly.HTMLElement) {
 		text := strings.TrimSpace(e.Text)
 		dateStr := strings.TrimPrefix(text, "Published: ")
-		t, err := normalizeTime(dateStr)
+		t, err := normalizeTime(dateStr, c.now())
 		if err != nil {
-			errs.Errs = append(errs.Errs, err)
+			p.Warnings = append(p.Warnings, &FieldError{Field: "Published", Raw: dateStr, Err: err})
 			return
 		}
 		p.Published = t
 	})
-	col.OnHTML(".UnitHeader-titleHeading", func(e *colly.HTMLElement) {
+	col.OnHTML(sel.UnitTitle, func(e *colly.HTMLElement) {
 		for next := e.DOM.Next(); ; next = next.Next() {
 			switch next.Text() {
 			case "command":
@@ -181,13 +1012,62 @@ func (c *client) DescribePackage(req DescribePackageRequest) (*Package, error) {
 				p.IsModule = true
 			default:
 				if !p.IsPackage && !p.IsModule {
-					errs.Errs = append(errs.Errs, fmt.Errorf("IsPackage=false after parsing page for '%s', this probably indicates a parsing bug", req.Package))
+					sectionErrs = append(sectionErrs, fmt.Errorf("IsPackage=false after parsing page for '%s', this probably indicates a parsing bug", req.Package))
 				}
 				return
 			}
 		}
 	})
-	col.OnHTML(".UnitReadme-content img", func(e *colly.HTMLElement) {
+	col.OnHTML(sel.UnitMetaDescription, func(e *colly.HTMLElement) {
+		// prefer the plain description, og:description is only a fallback
+		if p.MetaDescription != "" && e.Attr("name") != "description" {
+			return
+		}
+		if content := strings.TrimSpace(e.Attr("content")); content != "" {
+			p.MetaDescription = content
+		}
+	})
+	col.OnHTML(sel.UnitAuthor, func(e *colly.HTMLElement) {
+		for _, author := range splitAuthors(e.Attr("content")) {
+			if !slices.Contains(p.Maintainers, author) {
+				p.Maintainers = append(p.Maintainers, author)
+			}
+		}
+	})
+	col.OnHTML(sel.DocOverview, func(e *colly.HTMLElement) {
+		if overview := strings.TrimSpace(e.DOM.Find("p").First().Text()); overview != "" {
+			p.setSynopsis(overview, SynopsisSourceDocOverview, false)
+		}
+	})
+	for selector, count := range map[string]*int{
+		sel.DocIndexFunction: &p.FunctionCount,
+		sel.DocIndexType:     &p.TypeCount,
+		sel.DocIndexMethod:   &p.MethodCount,
+		sel.DocConstant:      &p.ConstCount,
+		sel.DocVariable:      &p.VarCount,
+	} {
+		col.OnHTML(selector, func(e *colly.HTMLElement) {
+			*count++
+		})
+	}
+	col.OnHTML(sel.SourceFiles, func(e *colly.HTMLElement) {
+		if href, ok := e.DOM.Find(sel.SourceFilesDirLink).First().Attr("href"); ok {
+			p.SourceCodeURL = resolveURL(e.Request.URL, href)
+			return
+		}
+		// no "View all Source files" link, use the directory of the first file
+		if href, ok := e.DOM.Find(sel.SourceFilesFileLink).First().Attr("href"); ok {
+			fileURL := resolveURL(e.Request.URL, href)
+			if i := strings.LastIndex(fileURL, "/"); i > 0 {
+				p.SourceCodeURL = strings.Replace(fileURL[:i], "/blob/", "/tree/", 1)
+			}
+		}
+	})
+	onTruncation(col, sel, func(section string) {
+		p.Truncated = true
+		p.TruncatedSections = append(p.TruncatedSections, section)
+	})
+	col.OnHTML(sel.ReadmeImages, func(e *colly.HTMLElement) {
 		alt, _ := e.DOM.Attr("alt")
 		src, _ := e.DOM.Attr("src")
 		// URL must be absolute
@@ -203,6 +1083,9 @@ func (c *client) DescribePackage(req DescribePackageRequest) (*Package, error) {
 			Alt: alt,
 			URL: url,
 		})
+		if percent, ok := parseCoverageBadge(alt, src); ok && !p.hasCoverage {
+			p.CoveragePercent, p.hasCoverage = percent, true
+		}
 	})
 
 	col.OnError(func(r *colly.Response, e error) {
@@ -212,37 +1095,270 @@ func (c *client) DescribePackage(req DescribePackageRequest) (*Package, error) {
 		}
 		errs.Errs = append(errs.Errs, fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e))
 	})
-	col.Visit(fmt.Sprintf("%s/%s", c.baseURL, req.Package))
+	col.Visit(unitURL)
 	if len(errs.Errs) != 0 {
 		return nil, errs
 	}
+	if len(sectionErrs) > 0 {
+		if !req.MinimalOK {
+			return nil, &ErrorList{Errs: sectionErrs}
+		}
+		var missing []error
+		for _, header := range []struct {
+			field  string
+			parsed bool
+		}{{"Version", p.Version != ""}, {"Published", p.Published != ""}, {"License", hasLicense}} {
+			if !header.parsed {
+				missing = append(missing, &FieldError{Field: header.field, Err: errElementMissing})
+			}
+		}
+		if len(missing) > 0 {
+			return nil, &ErrorList{Errs: append(missing, sectionErrs...)}
+		}
+		p.Warnings = append(p.Warnings, sectionErrs...)
+	}
+	if resolvedVersion != "" {
+		p.Version = resolvedVersion
+	}
+	p.ModuleVersion = semver.Major(p.Version)
+	p.Repository = resolveRepository(p.Package, p.Repository)
+	if len(p.MajorVersions) == 0 {
+		p.MajorVersions = []string{inferModulePath(p.Package)}
+	}
+	p.setMaintainers()
+	p.DocumentedSymbolCount = p.FunctionCount + p.TypeCount + p.MethodCount + p.ConstCount + p.VarCount
+	if !outdated {
+		p.IsLatest = true
+		p.LatestVersion = p.Version
+	}
+	if stats != nil {
+		p.Stats = stats.finish()
+	}
 	return p, nil
 }
 
+// declarationText returns the code of a documentation declaration as shown
+func declarationText(decl *goquery.Selection) string {
+	return strings.TrimSpace(decl.Find("pre").Text())
+}
+
+type Symbol struct {
+	Package     string
+	Name        string
+	Kind        string // e.g. "function", "type" or "method"
+	URL         string
+	Declaration string
+	Synopsis    string
+	// Truncated is set when the documentation was collapsed or cut short on
+	// pkg.go.dev, so the symbol may be missing or incomplete
+	Truncated bool
+}
+
+// Symbol looks up a single exported symbol (e.g. "Foo" or "Type.Method") in the
+// documentation of pkg, returning ErrNotFound when there's no such symbol
+func (c *client) Symbol(ctx context.Context, pkg, symbolName string) (*Symbol, error) {
+	if c.baseURLErr != nil {
+		return nil, c.baseURLErr
+	}
+	col := c.newCollector()
+	col.Context = ctx
+	sym := &Symbol{
+		Package: pkg,
+		Name:    symbolName,
+		URL:     c.pageURL(pkg) + "#" + symbolName,
+	}
+	var found bool
+	var err error
+
+	onTruncation(col, c.selectors, func(section string) {
+		sym.Truncated = sym.Truncated || section == "documentation"
+	})
+	col.OnHTML(fmt.Sprintf("[id=%q]", symbolName), func(e *colly.HTMLElement) {
+		if found {
+			return
+		}
+		found = true
+		sym.Kind = e.Attr("data-kind")
+		// constants and variables are named within their block's declaration
+		decl := e.DOM.Closest(c.selectors.DocDeclaration)
+		if decl.Length() == 0 {
+			decl = e.DOM.NextAllFiltered(c.selectors.DocDeclaration).First()
+		}
+		sym.Declaration = declarationText(decl)
+		synopsis := decl.NextAllFiltered("p").First()
+		if decl.Length() == 0 {
+			synopsis = e.DOM.NextAllFiltered("p").First()
+		}
+		sym.Synopsis = strings.TrimSpace(synopsis.Text())
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		if r.StatusCode == 404 {
+			err = ErrNotFound
+			return
+		}
+		err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
+	})
+	col.Visit(c.pageURL(pkg))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrNotFound
+	}
+	return sym, nil
+}
+
 type Versions struct {
-	Package  string
-	Versions []Version
+	Package    string
+	Repository string
+	Versions   []Version
+	Stats      *Stats
+	// Warnings lists the anomalies that didn't prevent listing the versions,
+	// like dates that couldn't be parsed, as *FieldError
+	Warnings []error
+	// Response describes the response of the versions tab
+	Response ResponseMeta
 }
 
 type Version struct {
 	MajorVersion string
 	FullVersion  string
 	Date         string
+	Repository   string // repository of the module, copied from Versions
+	Retracted    bool
+	// Vulnerabilities lists the IDs of the vulnerability reports affecting the
+	// version, like GO-2022-0123, empty when none are known
+	Vulnerabilities []string
+	// Changes lists the symbols added in the version, when the versions tab
+	// details them
+	Changes []Change
+}
+
+// ErrNilDate is returned by the PublishedTime methods when the date is empty,
+// because it wasn't shown or couldn't be parsed
+var ErrNilDate = errors.New("no date")
+
+// parseDate parses a date as normalized by normalizeTime
+func parseDate(date string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, ErrNilDate
+	}
+	return time.Parse(time.DateOnly, date)
+}
+
+// PublishedTime parses Date, returning ErrNilDate when it's empty
+func (v Version) PublishedTime() (time.Time, error) {
+	t, err := parseDate(v.Date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing date of version '%s': %w", v.FullVersion, err)
+	}
+	return t, nil
+}
+
+// addVulnerabilities adds the IDs of the vulnerability reports linked from s
+// that aren't in ids yet
+func addVulnerabilities(ids []string, s *goquery.Selection, selector string) []string {
+	s.Find(selector).Each(func(i int, link *goquery.Selection) {
+		id := path.Base(link.AttrOr("href", ""))
+		for _, known := range ids {
+			if known == id {
+				return
+			}
+		}
+		ids = append(ids, id)
+	})
+	return ids
+}
+
+// ChangelogURL returns the page of the version's release on the repository
+// host, or "" when the host is unknown
+func (v Version) ChangelogURL() string {
+	if v.Repository == "" || v.FullVersion == "" {
+		return ""
+	}
+	repoURL := v.Repository
+	if !strings.Contains(repoURL, "://") {
+		repoURL = normalizeRepoURL(repoURL)
+	}
+	repoURL = strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git")
+	tag := url.PathEscape(v.FullVersion)
+	switch IdentifyGitHost(repoURL) {
+	case GitHostGitHub, GitHostCodeberg:
+		return fmt.Sprintf("%s/releases/tag/%s", repoURL, tag)
+	case GitHostGitLab:
+		return fmt.Sprintf("%s/-/tags/%s", repoURL, tag)
+	default:
+		return ""
+	}
 }
 
 type Change struct {
 	URL            string
 	Symbol         string
 	SymbolSynopsis string
+	// ImpactedPackages lists the dependent packages broken by the change,
+	// empty when the page doesn't say
+	ImpactedPackages []string
+}
+
+// parseChange reads a change from an entry of a version's changes
+func parseChange(entry *goquery.Selection, page *url.URL, sel Selectors) Change {
+	link := entry.Find("a[href]").First()
+	change := Change{
+		URL:            resolveURL(page, link.AttrOr("href", "")),
+		Symbol:         strings.TrimSpace(link.Text()),
+		SymbolSynopsis: strings.TrimSpace(entry.Find(sel.VersionChangeSynopsis).First().Text()),
+	}
+	if u, err := url.Parse(change.URL); err == nil && u.Fragment != "" {
+		change.Symbol = u.Fragment
+	}
+	entry.NextUntil(sel.VersionChange).Filter(sel.VersionChangeImpacted).Find("a").Each(func(_ int, s *goquery.Selection) {
+		if pkg := strings.TrimSpace(s.Text()); pkg != "" {
+			change.ImpactedPackages = append(change.ImpactedPackages, pkg)
+		}
+	})
+	return change
 }
 
-func normalizeTime(s string) (string, error) {
+// resolveURL resolves a link found on a page against the page's URL. Unlike
+// colly's AbsoluteURL, it keeps fragments.
+func resolveURL(page *url.URL, href string) string {
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	return page.ResolveReference(ref).String()
+}
+
+// WithTimezone sets the time zone dates are normalized in, like Published,
+// which is UTC by default so that pages scraped on different machines give
+// the same dates. Relative dates like "2 hours ago" are resolved in it, and
+// the times of ResponseMeta are given in it. A nil location keeps UTC.
+func WithTimezone(loc *time.Location) func(c *client) {
+	return func(c *client) {
+		if loc == nil {
+			loc = time.UTC
+		}
+		c.location = loc
+	}
+}
+
+// now returns the current time in the client's time zone
+func (c *client) now() time.Time {
+	return c.clock().In(c.location)
+}
+
+// normalizeTime formats a date shown by pkg.go.dev as "2006-01-02".
+// Relative dates are resolved from now, and so in its time zone; absolute
+// ones are taken as is.
+func normalizeTime(s string, now time.Time) (string, error) {
 	var absTime time.Time
 
 	if s == "today" {
-		absTime = time.Now()
+		absTime = now
+	} else if s == "yesterday" {
+		absTime = now.AddDate(0, 0, -1)
 	} else if strings.Contains(s, "ago") {
-		now := time.Now()
 		split := strings.Split(s, " ")
 		quantityStr := split[0]
 		quantity, err := strconv.ParseInt(quantityStr, 10, 64)
@@ -273,52 +1389,118 @@ func normalizeTime(s string) (string, error) {
 }
 
 type VersionsRequest struct {
-	Package string
+	Package      string // import path, or a pkg.go.dev URL of the package
+	CollectStats bool
+	// UseGOPROXY lists the versions from the module proxy's structured
+	// endpoints instead of scraping pkg.go.dev. The proxy doesn't know about
+	// retractions or repositories, and CollectStats is ignored.
+	UseGOPROXY bool
+	// Fresh skips WithCache's entries, including remembered not found answers
+	Fresh bool
+	// Context cancels the request, defaulting to context.Background
+	Context context.Context
 }
 
 func (c *client) Versions(req VersionsRequest) (*Versions, error) {
+	return c.scrapeVersions(req, nil)
+}
+
+// scrapeVersions lists the versions of a package, passing each one to emit
+// as it's parsed when emit isn't nil. Parsing stops once emit returns false
+// or a version fails WithStrictVersions.
+func (c *client) scrapeVersions(req VersionsRequest, emit func(Version) bool) (*Versions, error) {
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req.Package, _ = c.splitPagePath(req.Package)
+	if req.UseGOPROXY {
+		versions, err := c.proxyVersions(ctx, req.Package)
+		if err == nil && emit != nil {
+			for _, v := range versions.Versions {
+				if !emit(v) {
+					break
+				}
+			}
+		}
+		return versions, err
+	}
+	if c.baseURLErr != nil {
+		return nil, c.baseURLErr
+	}
 	col := c.newCollector()
+	col.Context = ctx
+	if req.Fresh {
+		col.Context = withFresh(col.Context)
+	}
 	errs := &ErrorList{}
+	var stats *Stats
+	if req.CollectStats {
+		stats = trackStats(col)
+	}
 
+	sel := c.selectors
 	versions := &Versions{Package: req.Package}
-	col.OnHTML(".Versions-list", func(e *colly.HTMLElement) {
+	trackResponse(col, &versions.Response, c.now)
+	col.OnHTML(sel.UnitRepo, func(e *colly.HTMLElement) {
+		versions.Repository = strings.TrimSpace(e.DOM.Children().First().Text())
+	})
+	stopped := false
+	// emitLast passes the version just added to emit, the repository link
+	// coming before the list
+	emitLast := func() bool {
+		if emit == nil {
+			return true
+		}
+		if len(errs.Errs) > 0 {
+			return false
+		}
+		v := versions.Versions[len(versions.Versions)-1]
+		v.Repository = resolveRepository(req.Package, versions.Repository)
+		return emit(v)
+	}
+	col.OnHTML(sel.VersionsList, func(e *colly.HTMLElement) {
+		if stopped {
+			return
+		}
 		var curVersion Version
-		var curMajorVersion string
-		e.DOM.Children().Each(func(i int, s *goquery.Selection) {
-			if s.HasClass("Version-major") {
-				mv := strings.TrimSpace(s.Text())
-				if mv != "" {
+		// gopkg.in paths only have the versions of the major they name
+		curMajorVersion := gopkgInMajor(req.Package)
+		e.DOM.Children().EachWithBreak(func(i int, s *goquery.Selection) bool {
+			switch {
+			case s.Is(sel.VersionMajor):
+				if mv := majorVersionLabel(strings.TrimSpace(s.Text())); mv != "" {
 					curMajorVersion = mv
 				}
-				curVersion.MajorVersion = curMajorVersion
-			}
-			if s.HasClass("Version-tag") {
-				version := s.Find(".js-versionLink").Text()
-				curVersion.FullVersion = version
-			}
-			if s.HasClass("Version-commitTime") {
-				dateStr := strings.TrimSpace(s.Text())
-				t, err := normalizeTime(dateStr)
-				if err != nil {
+			case s.Is(sel.VersionTag):
+				curVersion.FullVersion = strings.TrimSpace(s.Find(sel.VersionLink).Text())
+				if err := c.reportVersion(curVersion.FullVersion, &versions.Warnings); err != nil {
 					errs.Errs = append(errs.Errs, err)
-					return
 				}
-				curVersion.Date = t
-				versions.Versions = append(versions.Versions, curVersion)
+				s.Find(sel.VersionBadge).Each(func(_ int, badge *goquery.Selection) {
+					if strings.EqualFold(strings.TrimSpace(badge.Text()), "retracted") {
+						curVersion.Retracted = true
+					}
+				})
+				curVersion.Vulnerabilities = addVulnerabilities(curVersion.Vulnerabilities, s, sel.VersionVulnerability)
+			case s.Is(sel.VersionCommitTime):
+				curVersion.Vulnerabilities = addVulnerabilities(curVersion.Vulnerabilities, s, sel.VersionVulnerability)
+				addVersionRow(versions, curVersion, curMajorVersion, s.Text(), c.now())
 				curVersion = Version{}
-			}
-			if s.HasClass("Version-details") {
-				s.Find(".Version-summary").Find("span").Remove()
-				dateStr := strings.TrimSpace(s.Find(".Version-summary").Text())
-				t, err := normalizeTime(dateStr)
-				if err != nil {
-					println("error in version details: " + err.Error())
-					return
-				}
-				curVersion.Date = t
-				versions.Versions = append(versions.Versions, curVersion)
+				stopped = !emitLast()
+			case s.Is(sel.VersionDetails):
+				curVersion.Vulnerabilities = addVulnerabilities(curVersion.Vulnerabilities, s, sel.VersionVulnerability)
+				s.Find(sel.VersionChange).Each(func(_ int, entry *goquery.Selection) {
+					curVersion.Changes = append(curVersion.Changes, parseChange(entry, e.Request.URL, sel))
+				})
+				// the summary holds the date next to decorative spans
+				summary := s.Find(sel.VersionSummary).First()
+				summary.Find("span").Remove()
+				addVersionRow(versions, curVersion, curMajorVersion, summary.Text(), c.now())
 				curVersion = Version{}
+				stopped = !emitLast()
 			}
+			return !stopped
 		})
 	})
 
@@ -330,130 +1512,557 @@ func (c *client) Versions(req VersionsRequest) (*Versions, error) {
 		errs.Errs = append(errs.Errs, fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e))
 	})
 
-	col.Visit(fmt.Sprintf("%s/%s?tab=versions", c.baseURL, req.Package))
-	if len(errs.Errs) > 0 {
-		return nil, errs
+	col.Visit(c.pageURL(req.Package) + "?tab=versions")
+	if len(errs.Errs) > 0 {
+		return nil, errs
+	}
+	versions.Repository = resolveRepository(req.Package, versions.Repository)
+	for i := range versions.Versions {
+		versions.Versions[i].Repository = versions.Repository
+	}
+	if stats != nil {
+		versions.Stats = stats.finish()
+	}
+	return versions, nil
+}
+
+// LatestVersionByModule returns the latest stable version of the module
+// providing module, which may be given as the path of any of its packages.
+// ErrNotFound is returned when the module has no stable version.
+func (c *client) LatestVersionByModule(ctx context.Context, module string) (Version, error) {
+	root, err := c.ResolveModuleForPackage(ctx, module)
+	if err != nil {
+		return Version{}, err
+	}
+	versions, err := c.Versions(VersionsRequest{Package: root})
+	if err != nil {
+		return Version{}, fmt.Errorf("listing versions of '%s': %w", root, err)
+	}
+	latest, ok := versions.Stable()
+	if !ok {
+		return Version{}, fmt.Errorf("%w: no stable version of '%s'", ErrNotFound, root)
+	}
+	return latest, nil
+}
+
+// addVersionRow completes a row of the versions list once its date cell is
+// reached. Rows only carry a major version when they start a new major, so
+// the current one is passed in. A date that can't be parsed is left empty
+// and warned about. Relative dates are resolved from now.
+func addVersionRow(versions *Versions, row Version, majorVersion, dateStr string, now time.Time) {
+	dateStr = strings.TrimSpace(dateStr)
+	t, err := normalizeTime(dateStr, now)
+	if err != nil {
+		versions.Warnings = append(versions.Warnings, &FieldError{
+			Field: "Date",
+			Raw:   dateStr,
+			Err:   fmt.Errorf("parsing date of version '%s': %w", row.FullVersion, err),
+		})
+	}
+	row.MajorVersion = majorVersion
+	row.Date = t
+	versions.Versions = append(versions.Versions, row)
+}
+
+// Latest returns the most recently published version. When several versions
+// share a date, the lowest FullVersion wins.
+func (v *Versions) Latest() (Version, bool) {
+	return pickVersion(v.Versions, true, nil)
+}
+
+// NewestVersion is an alias for Latest
+func (v *Versions) NewestVersion() (Version, bool) {
+	return v.Latest()
+}
+
+// OldestVersion returns the earliest published version, breaking ties the same
+// way as Latest
+func (v *Versions) OldestVersion() (Version, bool) {
+	return pickVersion(v.Versions, false, nil)
+}
+
+// Since returns the versions published on or after t, in list order. Dates
+// only have a day's precision, so a version published on t's day is kept
+// when t is midnight UTC. Versions without a date are left out.
+func (v *Versions) Since(t time.Time) []Version {
+	return v.publishedWithin(func(date time.Time) bool { return !date.Before(t) })
+}
+
+// Before returns the versions published before t, in list order
+func (v *Versions) Before(t time.Time) []Version {
+	return v.publishedWithin(func(date time.Time) bool { return date.Before(t) })
+}
+
+// Between returns the versions published from from up to but excluding to,
+// in list order
+func (v *Versions) Between(from, to time.Time) []Version {
+	return v.publishedWithin(func(date time.Time) bool { return !date.Before(from) && date.Before(to) })
+}
+
+// publishedWithin returns the versions whose date matches, never nil
+func (v *Versions) publishedWithin(match func(date time.Time) bool) []Version {
+	matched := []Version{}
+	for _, ver := range v.Versions {
+		if date, err := ver.PublishedTime(); err == nil && match(date) {
+			matched = append(matched, ver)
+		}
+	}
+	return matched
+}
+
+// Stable returns the most recently published version that is v1 or above and
+// not a prerelease or pseudo-version, breaking ties the same way as Latest
+func (v *Versions) Stable() (Version, bool) {
+	return pickVersion(v.Versions, true, func(ver Version) bool {
+		return semver.IsValid(ver.FullVersion) &&
+			semver.Prerelease(ver.FullVersion) == "" &&
+			semver.Major(ver.FullVersion) != "v0"
+	})
+}
+
+// MajorLines returns the distinct major versions listed, like "v1" and "v2",
+// sorted numerically so that v10 comes after v9. Versions without a
+// MajorVersion count for the major of their FullVersion. It's never nil.
+func (v *Versions) MajorLines() []string {
+	lines := []string{}
+	for _, ver := range v.Versions {
+		major := ver.MajorVersion
+		if major == "" {
+			major = semver.Major(ver.FullVersion)
+		}
+		if major != "" && !slices.Contains(lines, major) {
+			lines = append(lines, major)
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, aErr := strconv.Atoi(strings.TrimPrefix(lines[i], "v"))
+		b, bErr := strconv.Atoi(strings.TrimPrefix(lines[j], "v"))
+		if aErr != nil || bErr != nil {
+			// unparseable majors go last
+			return aErr == nil
+		}
+		return a < b
+	})
+	return lines
+}
+
+// pickVersion returns the newest (or oldest) of the versions accepted by keep,
+// preferring the lowest FullVersion among versions with the same date
+func pickVersion(versions []Version, newest bool, keep func(Version) bool) (Version, bool) {
+	var best Version
+	found := false
+	for _, ver := range versions {
+		if keep != nil && !keep(ver) {
+			continue
+		}
+		switch {
+		case !found:
+			best, found = ver, true
+		case ver.Date == best.Date:
+			if ver.FullVersion < best.FullVersion {
+				best = ver
+			}
+		case (ver.Date > best.Date) == newest:
+			best = ver
+		}
+	}
+	return best, found
+}
+
+// maxSearchPageSize is the largest page size pkg.go.dev's search accepts
+const maxSearchPageSize = 100
+
+// SearchRequest describes a search. Pages are fetched until Limit results
+// were collected, a page comes back empty or MaxPages pages were visited, so
+// at most MaxPages*PageSize results are returned whatever the Limit.
+type SearchRequest struct {
+	// Query may use the operators author:, description: and license:, with
+	// values quoted when they have spaces, see BuildQuery
+	Query string
+	// Limit caps the number of results. Zero or less returns the first page
+	// as is, whatever its size.
+	Limit int
+	// MaxPages caps the pages visited, defaulting to the client's WithMaxPages
+	MaxPages int
+	// PageSize is the number of results per page, up to 100. Zero uses
+	// pkg.go.dev's default of 25.
+	PageSize int
+	// Page fetches only that page, starting at 1, returning up to Limit of
+	// its results. Zero paginates from the first page.
+	Page         int
+	CollectStats bool
+	// ExcludeRetracted drops retracted results, OnlyRetracted keeps nothing
+	// but them. They're mutually exclusive.
+	ExcludeRetracted bool
+	OnlyRetracted    bool
+	// PathPrefix keeps only the results at or below an import path, like
+	// "github.com/myorg", matching whole path elements. pkg.go.dev can't
+	// filter by path, so more pages are fetched until Limit results match.
+	PathPrefix string
+	// SortBy orders the results once all pages are fetched and filtered,
+	// keeping pkg.go.dev's relevance order by default
+	SortBy SearchSort
+	// Context cancels the fetching of further pages, defaulting to
+	// context.Background
+	Context context.Context
+	// Cursor continues a search from the NextCursor of its previous results,
+	// which also carries Query and PageSize. It can't be combined with Page.
+	Cursor string
+}
+
+// SearchSort is the order of the results returned by Search
+type SearchSort int
+
+const (
+	SearchSortRelevance  SearchSort = iota // as ranked by pkg.go.dev
+	SearchSortImportedBy                   // most imported first
+	SearchSortPublished                    // newest first, unknown dates last
+	SearchSortName                         // by import path, alphabetically
+)
+
+func (s SearchSort) String() string {
+	switch s {
+	case SearchSortRelevance:
+		return "Relevance"
+	case SearchSortImportedBy:
+		return "ImportedBy"
+	case SearchSortPublished:
+		return "Published"
+	case SearchSortName:
+		return "Name"
+	default:
+		return "Unknown"
 	}
-	return versions, nil
 }
 
-type SearchRequest struct {
-	Query string
-	Limit int
+// sortSearchResults orders results by s, or in the reverse order when
+// reverse is set. The sort is stable, so ties keep their relevance order.
+func sortSearchResults(results []SearchResult, s SearchSort, reverse bool) {
+	var less func(a, b SearchResult) bool
+	switch s {
+	case SearchSortImportedBy:
+		less = func(a, b SearchResult) bool { return a.ImportedBy > b.ImportedBy }
+	case SearchSortPublished:
+		less = func(a, b SearchResult) bool {
+			at, aErr := a.PublishedTime()
+			bt, bErr := b.PublishedTime()
+			if aErr != nil || bErr != nil {
+				return aErr == nil && bErr != nil
+			}
+			return at.After(bt)
+		}
+	case SearchSortName:
+		less = func(a, b SearchResult) bool { return a.Package < b.Package }
+	default:
+		return
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if reverse {
+			return less(results[j], results[i])
+		}
+		return less(results[i], results[j])
+	})
 }
 
 type SearchResults struct {
 	Results []SearchResult
+	Scanned int // results read from pkg.go.dev, including the filtered out ones
+	Stats   *Stats
+	// Warnings lists the labels of the results that couldn't be parsed, as
+	// *FieldError
+	Warnings []error
+	// Responses describes the response of each page visited, in order
+	Responses []ResponseMeta
+	// NextCursor fetches the page after the last one visited when passed as
+	// SearchRequest.Cursor, empty once there are no more pages. The rest of a
+	// page cut short by Limit is skipped.
+	NextCursor string
+}
+
+// hasPathPrefix reports whether importPath is prefix or below it
+func hasPathPrefix(importPath, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return importPath == prefix || strings.HasPrefix(importPath, prefix+"/")
 }
 
 type SearchResult struct {
-	Package    string
-	Version    string
-	Published  string
-	ImportedBy int
-	License    string
-	Synopsis   string
+	Package       string
+	Version       string
+	Published     string // empty when the snippet has no parseable date
+	PublishedRaw  string // date text as shown in the snippet
+	ImportedBy    int    // zero when the snippet has no count
+	License       string
+	Synopsis      string
+	GitRepository string // inferred from Package, empty when the host is unknown
+	ModulePath    string // from the snippet when it names the module, otherwise inferred from Package
+	Retracted     bool
+	Stars         int // stars of the repository, only set by PackagesByStar
 }
 
-func (c *client) Search(req SearchRequest) (*SearchResults, error) {
-	col := c.newCollector()
-	results := &SearchResults{}
-	errs := &ErrorList{}
+// PublishedTime parses Published, returning ErrNilDate when it's empty
+func (r SearchResult) PublishedTime() (time.Time, error) {
+	t, err := parseDate(r.Published)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing published date of '%s': %w", r.Package, err)
+	}
+	return t, nil
+}
 
-	shouldContinue := true
-	page := 1
+// ErrInvalidRequest is returned, wrapped with the details, for requests with
+// invalid or conflicting fields
+var ErrInvalidRequest = errors.New("invalid request")
 
-	col.OnHTML(".SearchResults", func(e *colly.HTMLElement) {
-		// Check if there are any results
-		if e.DOM.Find(".SearchSnippet").Length() == 0 {
-			shouldContinue = false
-			return
+// ErrUnknownSortField is returned by SearchResults.SortBy for unsupported fields
+var ErrUnknownSortField = errors.New("unknown sort field")
+
+// isRetracted reports whether a search snippet carries the "retracted" badge
+func isRetracted(snippet *goquery.Selection, sel Selectors) bool {
+	retracted := false
+	snippet.Find(sel.SearchBadge).EachWithBreak(func(i int, badge *goquery.Selection) bool {
+		retracted = strings.EqualFold(strings.TrimSpace(badge.Text()), "retracted")
+		return !retracted
+	})
+	return retracted
+}
+
+// searchSortFields are the fields accepted by SearchResults.SortBy
+var searchSortFields = map[string]SearchSort{
+	"importedby": SearchSortImportedBy,
+	"published":  SearchSortPublished,
+	"package":    SearchSortName,
+}
+
+// SortBy sorts the results in place by "importedby", "published" or "package",
+// in the orders of SearchSortImportedBy, SearchSortPublished and
+// SearchSortName or their reverse. Unknown dates come last when descending.
+// The sort is stable, so results that compare equal keep their relevance
+// order.
+func (r *SearchResults) SortBy(field string, ascending bool) error {
+	s, ok := searchSortFields[strings.ToLower(field)]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownSortField, field)
+	}
+	// only names are ascending by default
+	sortSearchResults(r.Results, s, ascending != (s == SearchSortName))
+	return nil
+}
+
+// Filter returns the results for which predicate returns true, leaving r
+// unchanged
+func (r *SearchResults) Filter(predicate func(SearchResult) bool) *SearchResults {
+	filtered := &SearchResults{Scanned: r.Scanned, Stats: r.Stats, Responses: r.Responses}
+	for _, result := range r.Results {
+		if predicate(result) {
+			filtered.Results = append(filtered.Results, result)
 		}
+	}
+	return filtered
+}
 
-		// Process each search result
-		e.DOM.Find(".SearchSnippet").Each(func(i int, s *goquery.Selection) {
-			if len(results.Results) >= req.Limit {
-				shouldContinue = false
-				return
-			}
+// Map returns the results transformed by fn, leaving r unchanged
+func (r *SearchResults) Map(fn func(SearchResult) SearchResult) *SearchResults {
+	mapped := &SearchResults{Results: make([]SearchResult, len(r.Results)), Scanned: r.Scanned, Stats: r.Stats, Responses: r.Responses}
+	for i, result := range r.Results {
+		mapped.Results[i] = fn(result)
+	}
+	return mapped
+}
 
-			// Extract package name from the title link
-			titleLink := s.Find(".SearchSnippet-headerContainer a").First()
-			pkg := strings.TrimSpace(titleLink.Text())
+// Search collects the results of req, fetching pages until Limit results
+// are found, then sorts them by SortBy. See SearchIter to go through the
+// results as pages are fetched.
+func (c *client) Search(req SearchRequest) (*SearchResults, error) {
+	sortBy := req.SortBy
+	req.SortBy = SearchSortRelevance
+	it := c.SearchIter(req)
+	if it.err != nil {
+		return nil, it.err
+	}
+	if sortBy < SearchSortRelevance || sortBy > SearchSortName {
+		return nil, fmt.Errorf("%w: unknown SortBy %d", ErrInvalidRequest, sortBy)
+	}
 
-			// Extract synopsis
-			synopsis := strings.TrimSpace(s.Find(".SearchSnippet-synopsis").Text())
+	results := &SearchResults{}
+	for {
+		result, err := it.Next()
+		if err != nil {
+			return nil, &ErrorList{Errs: []error{err}}
+		}
+		if result == nil {
+			break
+		}
+		results.Results = append(results.Results, *result)
+	}
+	results.Scanned = it.scanned
+	results.Responses = it.responses
+	results.NextCursor = it.NextCursor()
+	results.Warnings = it.warnings
+	sortSearchResults(results.Results, sortBy, false)
+	if it.stats != nil {
+		results.Stats = it.stats.finish()
+	}
 
-			// Extract metadata from the info section
-			infoSection := s.Find(".SearchSnippet-infoLabel")
+	return results, nil
+}
 
-			// Extract version from the strong tag in the version section
-			versionText := infoSection.Contents().Filter("span").Text()
-			version := ""
-			if versionParts := strings.Split(versionText, " published on "); len(versionParts) > 0 {
-				version = strings.TrimSpace(strings.Trim(versionParts[0], " \t\n\r"))
-			}
+// searchPage holds what was scraped from a single page of search results
+type searchPage struct {
+	results  []SearchResult
+	snippets int // snippets on the page, including filtered out ones
+	warnings []error
+	response ResponseMeta
+	next     string // query string of the page's link to the next one, if any
+}
 
-			// Extract published date
-			publishedDateStr := strings.TrimSpace(infoSection.Find("[data-test-id=snippet-published] strong").Text())
-			published, err := normalizeTime(publishedDateStr)
-			if err != nil {
-				errs.Errs = append(errs.Errs, fmt.Errorf("parsing published date '%s': %w", publishedDateStr, err))
-				published = publishedDateStr // Use original if parsing fails
-			}
+// searchPage fetches a single page of search results with a collector of its
+// own, so that nothing but the returned value carries over between pages
+func (c *client) searchPage(ctx context.Context, req SearchRequest, pageURL string, stats *Stats) (*searchPage, error) {
+	col := c.newCollector()
+	col.Context = ctx
+	if stats != nil {
+		stats.track(col)
+	}
+	sel := c.selectors
+	page := &searchPage{}
+	trackResponse(col, &page.response, c.now)
+	var err error
 
-			// Extract imported by count
-			importedByText := strings.TrimSpace(infoSection.Find("a[href*='tab=importedby'] strong").Text())
-			importedByStr := strings.ReplaceAll(importedByText, ",", "")
-			importedBy, err := strconv.Atoi(importedByStr)
-			if err != nil {
-				importedBy = 0
+	col.OnHTML(sel.SearchResults, func(e *colly.HTMLElement) {
+		e.DOM.Find(sel.SearchSnippet).Each(func(i int, s *goquery.Selection) {
+			page.snippets++
+			result, warnings := parseSearchSnippet(s, sel, c.now())
+			page.warnings = append(page.warnings, warnings...)
+			// snippets of new packages may have no version at all
+			if result.Version != "" {
+				if versionErr := c.reportVersion(result.Version, &page.warnings); versionErr != nil && err == nil {
+					err = fmt.Errorf("result '%s': %w", result.Package, versionErr)
+				}
 			}
-
-			// Extract license
-			license := strings.TrimSpace(infoSection.Find("[data-test-id=snippet-license] a").Text())
-			if license == "" {
-				license = strings.TrimSpace(infoSection.Find("[data-test-id=snippet-license]").Text())
+			if (req.ExcludeRetracted && result.Retracted) || (req.OnlyRetracted && !result.Retracted) {
+				return
 			}
-
-			result := SearchResult{
-				Package:    pkg,
-				Synopsis:   synopsis,
-				Version:    version,
-				Published:  published,
-				ImportedBy: importedBy,
-				License:    license,
+			if req.PathPrefix != "" && !hasPathPrefix(result.Package, req.PathPrefix) {
+				return
 			}
-			results.Results = append(results.Results, result)
+			page.results = append(page.results, result)
 		})
 	})
-
+	col.OnHTML(sel.SearchNextPage, func(e *colly.HTMLElement) {
+		if next, parseErr := url.Parse(e.Attr("href")); parseErr == nil && strings.HasSuffix(next.Path, "/search") {
+			page.next = next.RawQuery
+		}
+	})
 	col.OnError(func(r *colly.Response, e error) {
-		errs.Errs = append(errs.Errs, fmt.Errorf("error fetching %s: %w", r.Request.URL.String(), e))
-		shouldContinue = false
+		err = fmt.Errorf("error fetching %s: %w", r.Request.URL.String(), e)
 	})
 
-	// Start scraping from page 1
-	for shouldContinue && len(results.Results) < req.Limit {
-		url := fmt.Sprintf("%s/search?q=%s&page=%d", c.baseURL, req.Query, page)
-		err := col.Visit(url)
-		if err != nil {
-			errs.Errs = append(errs.Errs, fmt.Errorf("visiting page %d: %w", page, err))
-			break
+	if visitErr := col.Visit(pageURL); visitErr != nil && err == nil {
+		err = visitErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// parseSearchSnippet extracts a search result from its snippet. Snippets of
+// new or unusual packages lack some of the info labels, so missing or
+// unparseable fields are left at their zero value rather than failing.
+func parseSearchSnippet(s *goquery.Selection, sel Selectors, now time.Time) (SearchResult, []error) {
+	var warnings []error
+	// Extract package name from the title link
+	titleLink := s.Find(sel.SearchTitleLink).First()
+	pkg := strings.TrimSpace(titleLink.Text())
+
+	// Extract synopsis
+	synopsis := strings.TrimSpace(s.Find(sel.SearchSynopsis).Text())
+
+	// Extract metadata from the info section
+	infoSection := s.Find(sel.SearchInfo)
+
+	// Extract version from the strong tag in the version section
+	versionText := infoSection.Contents().Filter("span").Text()
+	version := ""
+	if versionParts := strings.Split(versionText, " published on "); len(versionParts) > 0 {
+		version = strings.TrimSpace(strings.Trim(versionParts[0], " \t\n\r"))
+	}
+
+	// Extract published date
+	publishedDateStr := strings.TrimSpace(infoSection.Find(sel.SearchPublished).Text())
+	published, err := normalizeTime(publishedDateStr, now)
+	if err != nil {
+		published = ""
+		if publishedDateStr != "" {
+			warnings = append(warnings, &FieldError{Field: "Published", Raw: publishedDateStr, Err: fmt.Errorf("result '%s': %w", pkg, err)})
 		}
-		page++
+	}
 
-		// Prevent infinite loops
-		if page > 10 {
-			break
+	// Extract imported by count
+	importedByText := strings.TrimSpace(infoSection.Find(sel.SearchImportedBy).Text())
+	importedByStr := strings.ReplaceAll(importedByText, ",", "")
+	importedBy, err := strconv.Atoi(importedByStr)
+	if err != nil {
+		importedBy = 0
+		if importedByText != "" {
+			warnings = append(warnings, &FieldError{Field: "ImportedBy", Raw: importedByText, Err: fmt.Errorf("result '%s': %w", pkg, err)})
 		}
 	}
 
-	if len(errs.Errs) > 0 {
-		return nil, errs
+	// Extract license
+	license := strings.TrimSpace(infoSection.Find(sel.SearchLicense).Find("a").Text())
+	if license == "" {
+		license = strings.TrimSpace(infoSection.Find(sel.SearchLicense).Text())
 	}
 
-	return results, nil
+	modulePath := strings.TrimSpace(s.Find(sel.SearchModule).First().Text())
+	if modulePath == "" {
+		modulePath = inferModulePath(pkg)
+	}
+
+	return SearchResult{
+		Package:       pkg,
+		Synopsis:      synopsis,
+		Version:       version,
+		Published:     published,
+		PublishedRaw:  publishedDateStr,
+		ImportedBy:    importedBy,
+		License:       license,
+		GitRepository: inferRepository(pkg),
+		ModulePath:    modulePath,
+		Retracted:     isRetracted(s, sel),
+	}, warnings
+}
+
+// Autocomplete suggests up to limit package paths starting with prefix, for
+// tab completion. pkg.go.dev has no suggestion endpoint, so a single page of
+// search results for the prefix is filtered. No suggestions isn't an error.
+func (c *client) Autocomplete(ctx context.Context, prefix string, limit int) ([]string, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("%w: limit must be positive, got %d", ErrInvalidRequest, limit)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	results, err := c.Search(SearchRequest{
+		Query:    prefix,
+		Limit:    maxSearchPageSize,
+		PageSize: maxSearchPageSize,
+		MaxPages: 1,
+		Context:  ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	suggestions := []string{}
+	for _, result := range results.Results {
+		if len(suggestions) >= limit {
+			break
+		}
+		if strings.HasPrefix(strings.ToLower(result.Package), strings.ToLower(prefix)) {
+			suggestions = append(suggestions, result.Package)
+		}
+	}
+	return suggestions, nil
 }
 
 type ImportsRequest struct {
@@ -467,6 +2076,46 @@ type Imports struct {
 	StandardLibraryImports []string
 }
 
+// All returns the standard library and other imports merged into one sorted
+// list without duplicates
+func (i *Imports) All() []string {
+	seen := map[string]bool{}
+	all := []string{}
+	for _, list := range [][]string{i.StandardLibraryImports, i.Imports} {
+		for _, imp := range list {
+			if seen[imp] {
+				continue
+			}
+			seen[imp] = true
+			all = append(all, imp)
+		}
+	}
+	sort.Strings(all)
+	return all
+}
+
+// Count returns the number of distinct imports
+func (i *Imports) Count() int {
+	return len(i.All())
+}
+
+// ExternalImportCount returns the number of imports outside the standard
+// library
+func (i *Imports) ExternalImportCount() int {
+	return len(i.Imports)
+}
+
+// StdlibImportCount returns the number of standard library imports
+func (i *Imports) StdlibImportCount() int {
+	return len(i.StandardLibraryImports)
+}
+
+// TotalImportCount returns the sum of ExternalImportCount and
+// StdlibImportCount. Unlike Count, it doesn't merge duplicates.
+func (i *Imports) TotalImportCount() int {
+	return i.ExternalImportCount() + i.StdlibImportCount()
+}
+
 func (c *client) Imports(req ImportsRequest) (*Imports, error) {
 	return nil, nil
 }
@@ -485,6 +2134,40 @@ func (c *client) Licenses(req LicensesRequest) ([]License, error) {
 	return nil, nil
 }
 
+// ListLicenses returns the SPDX identifiers pkg.go.dev recognizes, as offered
+// by the license filter of its search form, sorted
+func (c *client) ListLicenses(ctx context.Context) ([]string, error) {
+	if c.baseURLErr != nil {
+		return nil, c.baseURLErr
+	}
+	col := c.newCollector()
+	col.Context = ctx
+	seen := map[string]bool{}
+	var licenses []string
+	var err error
+
+	col.OnHTML(c.selectors.SearchLicenseFilter, func(e *colly.HTMLElement) {
+		id := strings.TrimSpace(e.Attr("value"))
+		if id == "" || seen[id] {
+			return
+		}
+		seen[id] = true
+		licenses = append(licenses, id)
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
+	})
+	col.Visit(fmt.Sprintf("%s/search?q=&sort=relevance&limit=1", c.baseURL))
+	if err != nil {
+		return nil, err
+	}
+	if len(licenses) == 0 {
+		return nil, fmt.Errorf("%w: no license filter on the search page", ErrUnexpectedContent)
+	}
+	sort.Strings(licenses)
+	return licenses, nil
+}
+
 // GitHostType represents the type of git hosting service
 type GitHostType int
 
@@ -494,10 +2177,88 @@ const (
 	GitHostGitLab
 	GitHostCodeberg
 	GitHostSourcehut
+	GitHostGitea // self-hosted instances registered with WithGiteaHost
 )
 
-// identifyGitHost determines the git hosting service from a repository URL
-func identifyGitHost(repoURL string) GitHostType {
+// gitHostNames are the names used by GitHostType's String and text marshaling
+var gitHostNames = map[GitHostType]string{
+	GitHostUnknown:   "unknown",
+	GitHostGitHub:    "github",
+	GitHostGitLab:    "gitlab",
+	GitHostCodeberg:  "codeberg",
+	GitHostSourcehut: "sourcehut",
+	GitHostGitea:     "gitea",
+}
+
+func (t GitHostType) String() string {
+	if name, ok := gitHostNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("GitHostType(%d)", int(t))
+}
+
+func (t GitHostType) MarshalText() ([]byte, error) {
+	if _, ok := gitHostNames[t]; !ok {
+		return nil, fmt.Errorf("unknown git host type %d", int(t))
+	}
+	return []byte(t.String()), nil
+}
+
+func (t *GitHostType) UnmarshalText(text []byte) error {
+	for hostType, name := range gitHostNames {
+		if strings.EqualFold(name, string(text)) {
+			*t = hostType
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown git host type '%s'", text)
+}
+
+// builtinGitHosts are the hosts IdentifyGitHost recognizes
+var builtinGitHosts = map[string]GitHostType{
+	"github.com":   GitHostGitHub,
+	"gitlab.com":   GitHostGitLab,
+	"codeberg.org": GitHostCodeberg,
+	"git.sr.ht":    GitHostSourcehut,
+}
+
+// WithGiteaHost registers the hostname of a self-hosted Gitea or Forgejo
+// instance, so that Sprinkle can fetch descriptions from it
+func WithGiteaHost(host string) func(c *client) {
+	return func(c *client) {
+		if c.gitHosts == nil {
+			c.gitHosts = map[string]GitHostType{}
+		}
+		c.gitHosts[strings.ToLower(host)] = GitHostGitea
+	}
+}
+
+// KnownHosts returns the hostnames the client recognizes as git hosts, both
+// builtin and registered with options like WithGiteaHost
+func (c *client) KnownHosts() map[string]GitHostType {
+	hosts := map[string]GitHostType{}
+	for host, hostType := range builtinGitHosts {
+		hosts[host] = hostType
+	}
+	for host, hostType := range c.gitHosts {
+		hosts[host] = hostType
+	}
+	return hosts
+}
+
+// identifyGitHost is IdentifyGitHost taking the hosts registered on the
+// client into account
+func (c *client) identifyGitHost(repoURL string) GitHostType {
+	if u, err := url.Parse(repoURL); err == nil {
+		if hostType, ok := c.gitHosts[strings.ToLower(u.Hostname())]; ok {
+			return hostType
+		}
+	}
+	return IdentifyGitHost(repoURL)
+}
+
+// IdentifyGitHost determines the git hosting service from a repository URL
+func IdentifyGitHost(repoURL string) GitHostType {
 	u, err := url.Parse(repoURL)
 	if err != nil {
 		return GitHostUnknown
@@ -517,6 +2278,30 @@ func identifyGitHost(repoURL string) GitHostType {
 	}
 }
 
+// ErrInvalidRepoURL is returned by NormalizeRepoURL for input that can't be
+// turned into a repository URL
+var ErrInvalidRepoURL = errors.New("invalid repository URL")
+
+// NormalizeRepoURL converts a repository URL, import path style location
+// ("github.com/foo/bar"), scp-like git remote ("git@github.com:foo/bar.git")
+// or ssh URL ("ssh://git@github.com/foo/bar.git") to a web-accessible https
+// URL
+func NormalizeRepoURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("%w: empty", ErrInvalidRepoURL)
+	}
+	normalized := normalizeRepoURL(raw)
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidRepoURL, err)
+	}
+	if u.Hostname() == "" || !strings.Contains(u.Hostname(), ".") || strings.HasPrefix(normalized, "https://git@") {
+		return "", fmt.Errorf("%w: no host in '%s'", ErrInvalidRepoURL, raw)
+	}
+	return normalized, nil
+}
+
 // normalizeRepoURL converts various repository URL formats to web-accessible URLs
 func normalizeRepoURL(repoURL string) string {
 	// Convert git+ssh URLs to https
@@ -529,39 +2314,201 @@ func normalizeRepoURL(repoURL string) string {
 		}
 	}
 
+	if strings.HasPrefix(repoURL, "ssh://") || strings.HasPrefix(repoURL, "git+ssh://") {
+		// ssh://git@github.com:22/user/repo.git -> https://github.com/user/repo,
+		// the ssh port means nothing over https
+		if u, err := url.Parse(repoURL); err == nil && u.Hostname() != "" {
+			return "https://" + u.Hostname() + strings.TrimSuffix(u.Path, ".git")
+		}
+	}
+
 	if strings.HasSuffix(repoURL, ".git") {
 		repoURL = strings.TrimSuffix(repoURL, ".git")
 	}
 
+	if repo, ok := gopkgInRepository(strings.TrimPrefix(repoURL, "https://")); ok {
+		// gopkg.in serves the git data, the project's page is on GitHub
+		return "https://" + repo
+	}
+
+	if strings.Contains(repoURL, "://") {
+		return repoURL
+	}
 	return "https://" + repoURL
 }
 
-func (c *client) fetchDescription(repoURL string) string {
-	if repoURL == "" {
+// interstitialMarkers are lowercased snippets of the anti-bot pages some hosts
+// serve before the real content
+var interstitialMarkers = [][]byte{
+	[]byte("checking your browser"),
+	[]byte("cf-browser-verification"),
+	[]byte("challenge-platform"),
+	[]byte("enable javascript and cookies to continue"),
+}
+
+func isInterstitial(body []byte) bool {
+	lower := bytes.ToLower(body)
+	for _, marker := range interstitialMarkers {
+		if bytes.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// visitRepo visits a repository page with the given collector, returning
+// ErrInterstitialBlocked when the host serves a browser check instead
+func (c *client) visitRepo(col *colly.Collector, repoURL string) error {
+	var err error
+	col.OnResponse(func(r *colly.Response) {
+		if isInterstitial(r.Body) {
+			err = ErrInterstitialBlocked
+		}
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		if isInterstitial(r.Body) {
+			err = ErrInterstitialBlocked
+			return
+		}
+		err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
+	})
+	visitErr := col.Visit(repoURL)
+	if err == nil && visitErr != nil {
+		err = fmt.Errorf("visiting %s: %w", repoURL, visitErr)
+	}
+	return err
+}
+
+// inferRepository returns the repository URL of an import path hosted on a
+// known git host, e.g. github.com/foo/bar/baz -> https://github.com/foo/bar
+func inferRepository(importPath string) string {
+	repoURL := normalizeRepoURL(importPath)
+	if IdentifyGitHost(repoURL) == GitHostUnknown {
+		return ""
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
 		return ""
 	}
+	return fmt.Sprintf("%s://%s/%s/%s", u.Scheme, u.Host, parts[0], parts[1])
+}
+
+// repoInfo is what Sprinkle scrapes from a repository's page
+type repoInfo struct {
+	description      string
+	issueCount       int
+	archived         bool
+	contributorCount int
+	stars            int
+	forkCount        int
+	watcherCount     int
+}
+
+// parseIssueCount parses an issue count like "1,234" or GitHub's abbreviated
+// "1.2k"
+func parseIssueCount(text string) (int, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if strings.HasSuffix(text, "k") {
+		thousands, err := strconv.ParseFloat(strings.TrimSuffix(text, "k"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("parsing issue count '%s': %w", text, err)
+		}
+		return int(thousands * 1000), nil
+	}
+	return parseCount(text)
+}
+
+func (c *client) fetchRepoInfo(repoURL string, options SprinkleOptions) (repoInfo, error) {
+	if repoURL == "" {
+		return repoInfo{}, nil
+	}
 
 	normalizedURL := normalizeRepoURL(repoURL)
-	hostType := identifyGitHost(normalizedURL)
+	hostType := c.identifyGitHost(normalizedURL)
 
 	switch hostType {
 	case GitHostGitHub:
-		return c.extractGitHubDescription(normalizedURL)
+		return c.extractGitHubInfo(normalizedURL, options&SprinkleNoNetworkCounts == 0)
 	case GitHostGitLab:
-		return c.extractGitLabDescription(normalizedURL)
-	case GitHostCodeberg:
-		return c.extractCodebergDescription(normalizedURL)
+		return c.extractGitLabInfo(normalizedURL)
+	case GitHostCodeberg, GitHostGitea:
+		// Codeberg runs Forgejo, a Gitea fork sharing its markup
+		return c.extractCodebergInfo(normalizedURL)
 	case GitHostSourcehut:
-		return c.extractSourcehutDescription(normalizedURL)
+		return c.extractSourcehutInfo(normalizedURL)
 	default:
-		return ""
+		return repoInfo{}, nil
 	}
 }
 
-// extractGitHubDescription extracts description from GitHub repository page
-func (c *client) extractGitHubDescription(repoURL string) string {
+// extractGitHubInfo scrapes a GitHub repository page into a repoInfo: its
+// description, open issues, contributors, stars and whether it's archived,
+// plus the fork and watcher counts when networkCounts is set
+func (c *client) extractGitHubInfo(repoURL string, networkCounts bool) (repoInfo, error) {
 	col := c.newCollector()
 	var description string
+	var issueCount, contributorCount, stars, forkCount, watcherCount int
+	var archived bool
+
+	col.OnHTML("div.archived-notice-badge", func(e *colly.HTMLElement) {
+		archived = true
+	})
+
+	col.OnHTML("#contributor-link", func(e *colly.HTMLElement) {
+		// like the issue count, the counter's title holds the exact count
+		text := e.DOM.Find(".Counter").AttrOr("title", "")
+		if text == "" {
+			text = e.DOM.Find(".Counter").Text()
+		}
+		if text == "" {
+			text = e.Text
+		}
+		contributorCount, _ = parseIssueCount(text)
+	})
+
+	col.OnHTML("#repo-stars-counter-star", func(e *colly.HTMLElement) {
+		text := e.Attr("title")
+		if text == "" {
+			text = e.Text
+		}
+		stars, _ = parseIssueCount(text)
+	})
+
+	if networkCounts {
+		col.OnHTML("#repo-network-counter", func(e *colly.HTMLElement) {
+			text := e.Attr("title")
+			if text == "" {
+				text = e.Text
+			}
+			forkCount, _ = parseIssueCount(text)
+		})
+
+		// the header's counter is gone from newer layouts, which show the
+		// count in the sidebar's link to the watchers
+		col.OnHTML("#repo-notifications-counter, a[href$='/watchers'] strong", func(e *colly.HTMLElement) {
+			if watcherCount != 0 {
+				return
+			}
+			text := e.Attr("title")
+			if text == "" {
+				text = e.Text
+			}
+			watcherCount, _ = parseIssueCount(text)
+		})
+	}
+
+	col.OnHTML("span#issues-repo-tab-count", func(e *colly.HTMLElement) {
+		// the text is abbreviated like "1.2k", the title holds the exact count
+		text := e.Attr("title")
+		if text == "" {
+			text = e.Text
+		}
+		issueCount, _ = parseIssueCount(text)
+	})
 
 	col.OnHTML("p[class*='f4']", func(e *colly.HTMLElement) {
 		if description == "" {
@@ -572,14 +2519,38 @@ func (c *client) extractGitHubDescription(repoURL string) string {
 		}
 	})
 
-	col.Visit(repoURL)
-	return description
+	if err := c.visitRepo(col, repoURL); err != nil {
+		return repoInfo{}, err
+	}
+	return repoInfo{
+		description:      description,
+		issueCount:       issueCount,
+		archived:         archived,
+		contributorCount: contributorCount,
+		stars:            stars,
+		forkCount:        forkCount,
+		watcherCount:     watcherCount,
+	}, nil
 }
 
-// extractGitLabDescription extracts description from GitLab repository page
-func (c *client) extractGitLabDescription(repoURL string) string {
+// extractGitLabInfo scrapes a GitLab project page into a repoInfo: its
+// description, open issues, contributors and stars
+func (c *client) extractGitLabInfo(repoURL string) (repoInfo, error) {
 	col := c.newCollector()
 	var description string
+	var issueCount, contributorCount, stars int
+
+	col.OnHTML(".issues_count", func(e *colly.HTMLElement) {
+		issueCount, _ = parseIssueCount(e.Text)
+	})
+
+	col.OnHTML(".contributors-count", func(e *colly.HTMLElement) {
+		contributorCount, _ = parseIssueCount(e.Text)
+	})
+
+	col.OnHTML(".star-count", func(e *colly.HTMLElement) {
+		stars, _ = parseIssueCount(e.Text)
+	})
 
 	col.OnHTML(".home-panel-description-markdown p", func(e *colly.HTMLElement) {
 		if description == "" {
@@ -587,12 +2558,15 @@ func (c *client) extractGitLabDescription(repoURL string) string {
 		}
 	})
 
-	col.Visit(repoURL)
-	return description
+	if err := c.visitRepo(col, repoURL); err != nil {
+		return repoInfo{}, err
+	}
+	return repoInfo{description: description, issueCount: issueCount, contributorCount: contributorCount, stars: stars}, nil
 }
 
-// extractCodebergDescription extracts description from Codeberg repository page
-func (c *client) extractCodebergDescription(repoURL string) string {
+// extractCodebergInfo scrapes a Codeberg or Gitea repository page into a
+// repoInfo, which only has the description
+func (c *client) extractCodebergInfo(repoURL string) (repoInfo, error) {
 	col := c.newCollector()
 	var description string
 
@@ -602,12 +2576,15 @@ func (c *client) extractCodebergDescription(repoURL string) string {
 		}
 	})
 
-	col.Visit(repoURL)
-	return description
+	if err := c.visitRepo(col, repoURL); err != nil {
+		return repoInfo{}, err
+	}
+	return repoInfo{description: description}, nil
 }
 
-// extractSourcehutDescription extracts description from Sourcehut repository page
-func (c *client) extractSourcehutDescription(repoURL string) string {
+// extractSourcehutInfo scrapes a Sourcehut repository page into a repoInfo,
+// which only has the description
+func (c *client) extractSourcehutInfo(repoURL string) (repoInfo, error) {
 	col := c.newCollector()
 	var description string
 
@@ -621,24 +2598,93 @@ func (c *client) extractSourcehutDescription(repoURL string) string {
 		}
 	})
 
-	col.Visit(repoURL)
-	return description
+	if err := c.visitRepo(col, repoURL); err != nil {
+		return repoInfo{}, err
+	}
+	return repoInfo{description: description}, nil
+}
+
+// hostedRepository returns the repository to fetch info from, resolving
+// repositories on unknown hosts through the go-import meta tag
+func (c *client) hostedRepository(importPath, repoURL string) string {
+	if repoURL != "" && c.identifyGitHost(normalizeRepoURL(repoURL)) == GitHostUnknown {
+		// vanity hosts like go.uber.org redirect the go command to the real one
+		if info, err := c.ResolveVanityImport(importPath); err == nil && info.VCS != "mod" {
+			return info.RepoRoot
+		}
+	}
+	return repoURL
 }
 
-// Sprinkle enhances a Package with additional metadata fetched from its repository
-func (c *client) Sprinkle(p *Package) error {
-	fmt.Println("here")
+// SprinkleOptions is a bitmask of options changing the behaviour of Sprinkle
+type SprinkleOptions uint
+
+const (
+	// SprinkleForce overwrites the synopsis even if it came from a more
+	// trustworthy source than the repository
+	SprinkleForce SprinkleOptions = 1 << iota
+	// SprinkleContributors sets ContributorCount, from GitHub or GitLab only
+	SprinkleContributors
+	// SprinkleNoNetworkCounts skips parsing the fork and watcher counts,
+	// leaving ForkCount and WatcherCount unset
+	SprinkleNoNetworkCounts
+)
+
+// Sprinkle enhances a Package with additional metadata fetched from its repository.
+// With WithPkgGoDevOnly the repository isn't fetched: only the synopsis is set,
+// from MetaDescription, and a warning naming the repository is added to the
+// Package. ErrExternalFetchDisabled is returned when there's no MetaDescription.
+func (c *client) Sprinkle(p *Package, opts ...SprinkleOptions) error {
+	var options SprinkleOptions
+	for _, opt := range opts {
+		options |= opt
+	}
 
 	if p == nil {
 		return fmt.Errorf("package is nil")
 	}
 
-	if p.Repository == "" {
+	if p.Repository == "" && p.MetaDescription == "" {
 		return fmt.Errorf("no repository URL available")
 	}
 
-	// Fetch description from repository
-	description := c.fetchDescription(p.Repository)
+	var info repoInfo
+	var err error
+	if c.pkgGoDevOnly {
+		if p.MetaDescription == "" {
+			return ErrExternalFetchDisabled
+		}
+		// the repository can't be fetched, but the summary from pkg.go.dev
+		// still makes a synopsis
+		if p.Repository != "" {
+			p.Warnings = append(p.Warnings, &FieldError{Field: "Repository", Raw: p.Repository, Err: ErrExternalFetchDisabled})
+		}
+	} else {
+		// Fetch description from repository
+		info, err = c.fetchRepoInfo(c.hostedRepository(p.Package, p.Repository), options)
+		p.IssueCount = info.issueCount
+		p.Stars = info.stars
+		if options&SprinkleNoNetworkCounts == 0 {
+			// only found on GitHub, so zero for other hosts
+			p.ForkCount, p.WatcherCount = info.forkCount, info.watcherCount
+		}
+		// archiving may also have been spotted on pkg.go.dev, so only set it
+		if info.archived {
+			p.IsArchived = true
+		}
+		if options&SprinkleContributors != 0 {
+			p.ContributorCount = info.contributorCount
+		}
+	}
+	description := info.description
+	source := SynopsisSourceRepository
+	if description == "" && p.MetaDescription != "" {
+		// fall back to the summary from pkg.go.dev itself
+		description, source, err = p.MetaDescription, SynopsisSourceMetaTag, nil
+	}
+	if err != nil {
+		return fmt.Errorf("fetching description from repository: %w", err)
+	}
 	if description == "" {
 		return fmt.Errorf("could not fetch description from repository")
 	}
@@ -649,9 +2695,7 @@ func (c *client) Sprinkle(p *Package) error {
 		description = description[:500] + "...>"
 	}
 
-	p.Synopsis = description
-
-	fmt.Println(p.Synopsis)
+	p.setSynopsis(description, source, options&SprinkleForce != 0)
 
 	return nil
 }
@@ -1,12 +1,23 @@
 package pkggodev
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/gocolly/colly/v2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -142,12 +153,18 @@ func TestClient_DescribePackage(t *testing.T) {
 				Published:                 "2000-02-03",
 				IsModule:                  true,
 				IsPackage:                 true,
+				IsLatest:                  true,
+				LatestVersion:             "fooversion",
+				MajorVersions:             []string{"somepackage"},
+				Warnings: []error{
+					&FieldError{Field: "Version", Raw: "fooversion", Err: ErrMalformedVersion{Raw: "fooversion"}},
+				},
 			},
 		},
 		{
 			name:          "package but not module",
 			html:          `<div class="UnitHeader-titleHeading">Heading</div><div>package</div><div>something else</div>`,
-			expectPackage: Package{Package: "somepackage", IsPackage: true},
+			expectPackage: Package{Package: "somepackage", IsPackage: true, IsLatest: true, MajorVersions: []string{"somepackage"}},
 		},
 		{
 			name:              "returns an error if IsPackage is false",
@@ -159,11 +176,6 @@ func TestClient_DescribePackage(t *testing.T) {
 			httpCode:          500,
 			expectErrContains: "Internal Server Error",
 		},
-		{
-			name:              "returns an error if the published date can't be parsed",
-			html:              `<div data-test-id="UnitHeader-commitTime">  Published: February 333, 20 </div>`,
-			expectErrContains: "parsing time",
-		},
 		{
 			name:              "returns error on 404",
 			httpCode:          404,
@@ -189,8 +201,4771 @@ func TestClient_DescribePackage(t *testing.T) {
 					return
 				}
 				assert.NoError(t, err)
+				assert.Equal(t, http.StatusOK, pkg.Response.StatusCode)
+				assert.Equal(t, "http://"+addr+"/somepackage", pkg.Response.FinalURL)
+				pkg.Response = ResponseMeta{}
 				assert.Equal(t, c.expectPackage, *pkg)
 			})
 		})
 	}
 }
+
+func TestClient_CookieJarSharedAcrossCalls(t *testing.T) {
+	var sawCookie bool
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("consent"); err == nil {
+			sawCookie = true
+		}
+		http.SetCookie(rw, &http.Cookie{Name: "consent", Value: "yes", Path: "/"})
+		rw.Write([]byte(`<div class="u-breakWord">foo</div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		_, err := client.ImportedBy(ImportedByRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.False(t, sawCookie)
+		_, err = client.ImportedBy(ImportedByRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.True(t, sawCookie)
+	})
+}
+
+func TestClient_ExtractDescriptionInterstitial(t *testing.T) {
+	cases := []struct {
+		name              string
+		html              string
+		httpCode          int
+		expectDescription string
+		expectErr         error
+	}{
+		{
+			name:              "happy case",
+			html:              `<div class="home-panel-description-markdown"><p> a description </p></div>`,
+			expectDescription: "a description",
+		},
+		{
+			name:      "browser check page",
+			html:      `<html><title>Just a moment...</title><p>Checking your browser before accessing gitlab.com</p></html>`,
+			expectErr: ErrInterstitialBlocked,
+		},
+		{
+			name:      "browser check page with error status",
+			html:      `<html><div id="cf-browser-verification"></div></html>`,
+			httpCode:  503,
+			expectErr: ErrInterstitialBlocked,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				if c.httpCode != 0 {
+					rw.WriteHeader(c.httpCode)
+				}
+				rw.Write([]byte(c.html))
+			}, func(addr string) {
+				client := New()
+				info, err := client.extractGitLabInfo("http://" + addr)
+				if c.expectErr != nil {
+					assert.ErrorIs(t, err, c.expectErr)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, c.expectDescription, info.description)
+			})
+		})
+	}
+}
+
+func TestClient_PackageExists(t *testing.T) {
+	cases := []struct {
+		name              string
+		httpCode          int
+		expectExists      bool
+		expectErrContains string
+	}{
+		{
+			name:         "exists",
+			httpCode:     200,
+			expectExists: true,
+		},
+		{
+			name:         "does not exist",
+			httpCode:     404,
+			expectExists: false,
+		},
+		{
+			name:              "other status codes return an error",
+			httpCode:          500,
+			expectErrContains: "Internal Server Error",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodHead, r.Method)
+				assert.Equal(t, "/somepackage", r.URL.Path)
+				rw.WriteHeader(c.httpCode)
+			}, func(addr string) {
+				client := New(WithBaseURL("http://" + addr))
+				exists, err := client.PackageExists(context.Background(), "somepackage")
+				if c.expectErrContains != "" {
+					assert.ErrorContains(t, err, c.expectErrContains)
+					assert.ErrorIs(t, err, ErrUnexpectedStatus)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, c.expectExists, exists)
+			})
+		})
+	}
+}
+
+func TestClient_PkgGoDevOnly(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<div class="u-breakWord">foo</div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://"+addr), WithPkgGoDevOnly())
+
+		importedBy, err := client.ImportedBy(ImportedByRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"foo"}, importedBy.ImportedBy)
+
+		err = client.Sprinkle(&Package{Package: "somepackage", Repository: "github.com/foo/bar"})
+		assert.ErrorIs(t, err, ErrExternalFetchDisabled)
+
+		// the summary from pkg.go.dev is used without fetching the repository
+		p := &Package{Package: "somepackage", Repository: "http://localhost.invalid/foo/bar", MetaDescription: "Package somepackage does things.", Stars: 3}
+		assert.NoError(t, client.Sprinkle(p))
+		assert.Equal(t, "Package somepackage does things.", p.Synopsis)
+		assert.Equal(t, SynopsisSourceMetaTag, p.SynopsisSource)
+		assert.Equal(t, 3, p.Stars)
+		if assert.Len(t, p.Warnings, 1) {
+			assert.ErrorIs(t, p.Warnings[0], ErrExternalFetchDisabled)
+		}
+
+		_, err = client.extractGitHubInfo("http://localhost.invalid/foo/bar", true)
+		assert.ErrorIs(t, err, colly.ErrForbiddenDomain)
+	})
+}
+
+func TestVersions_Pick(t *testing.T) {
+	versions := &Versions{Versions: []Version{
+		{MajorVersion: "v2", FullVersion: "v2.1.0-rc.1", Date: "2021-03-01"},
+		{MajorVersion: "v2", FullVersion: "v2.0.1", Date: "2021-02-01"},
+		{MajorVersion: "v2", FullVersion: "v2.0.0", Date: "2021-02-01"},
+		{MajorVersion: "v1", FullVersion: "v1.0.0", Date: "2020-01-01"},
+		{MajorVersion: "v0", FullVersion: "v0.9.0", Date: "2019-06-01"},
+		{MajorVersion: "v0", FullVersion: "v0.1.0", Date: "2019-06-01"},
+	}}
+
+	latest, ok := versions.Latest()
+	assert.True(t, ok)
+	assert.Equal(t, "v2.1.0-rc.1", latest.FullVersion)
+
+	newest, ok := versions.NewestVersion()
+	assert.True(t, ok)
+	assert.Equal(t, latest, newest)
+
+	stable, ok := versions.Stable()
+	assert.True(t, ok)
+	assert.Equal(t, "v2.0.0", stable.FullVersion)
+
+	oldest, ok := versions.OldestVersion()
+	assert.True(t, ok)
+	assert.Equal(t, "v0.1.0", oldest.FullVersion)
+
+	empty := &Versions{}
+	_, ok = empty.Latest()
+	assert.False(t, ok)
+	_, ok = empty.OldestVersion()
+	assert.False(t, ok)
+	_, ok = (&Versions{Versions: []Version{{FullVersion: "v0.1.0"}}}).Stable()
+	assert.False(t, ok)
+}
+
+func TestClient_Ping(t *testing.T) {
+	cases := []struct {
+		name      string
+		html      string
+		httpCode  int
+		expectErr error
+	}{
+		{
+			name: "happy case",
+			html: `<html><form action="/search"><input name="q"></form></html>`,
+		},
+		{
+			name:      "non-200 status",
+			httpCode:  503,
+			expectErr: ErrUnexpectedStatus,
+		},
+		{
+			name:      "missing search form",
+			html:      `<html><p>some other site</p></html>`,
+			expectErr: ErrUnexpectedContent,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				if c.httpCode != 0 {
+					rw.WriteHeader(c.httpCode)
+					return
+				}
+				rw.Write([]byte(c.html))
+			}, func(addr string) {
+				client := New(WithBaseURL("http://" + addr))
+				err := client.Ping(context.Background())
+				if c.expectErr != nil {
+					assert.ErrorIs(t, err, c.expectErr)
+					return
+				}
+				assert.NoError(t, err)
+			})
+		})
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		addr := listener.Addr().String()
+		listener.Close()
+
+		client := New(WithBaseURL("http://" + addr))
+		assert.ErrorIs(t, client.Ping(context.Background()), ErrUnreachable)
+	})
+}
+
+func TestInferRepository(t *testing.T) {
+	cases := []struct {
+		importPath string
+		expect     string
+	}{
+		{importPath: "github.com/foo/bar", expect: "https://github.com/foo/bar"},
+		{importPath: "github.com/foo/bar/v2/baz", expect: "https://github.com/foo/bar"},
+		{importPath: "gitlab.com/foo/bar/baz", expect: "https://gitlab.com/foo/bar"},
+		{importPath: "codeberg.org/foo/bar", expect: "https://codeberg.org/foo/bar"},
+		{importPath: "git.sr.ht/~foo/bar", expect: "https://git.sr.ht/~foo/bar"},
+		{importPath: "github.com/foo", expect: ""},
+		{importPath: "gopkg.in/yaml.v3", expect: "https://github.com/go-yaml/yaml"},
+		{importPath: "gopkg.in/mgo.v2/bson", expect: "https://github.com/go-mgo/mgo"},
+		{importPath: "gopkg.in/src-d/go-git.v4", expect: "https://github.com/src-d/go-git"},
+		{importPath: "golang.org/x/mod/semver", expect: ""},
+	}
+	for _, c := range cases {
+		t.Run(c.importPath, func(t *testing.T) {
+			assert.Equal(t, c.expect, inferRepository(c.importPath))
+		})
+	}
+}
+
+const searchSnippetsHTML = `
+<html><body><div class="SearchResults">
+<div class="SearchSnippet">
+  <div class="SearchSnippet-headerContainer"><h2><a href="/github.com/foo/bar/baz">github.com/foo/bar/baz</a></h2></div>
+  <p class="SearchSnippet-synopsis">Package baz does things.</p>
+  <div class="SearchSnippet-infoLabel">
+    <a href="/github.com/foo/bar/baz?tab=importedby"><span>Imported by </span><strong>1,234</strong></a>
+    <span><strong>v1.2.3</strong> published on <span data-test-id="snippet-published"><strong>Jan 2, 2021</strong></span></span>
+    <span data-test-id="snippet-license"><a href="/github.com/foo/bar/baz?tab=licenses">MIT</a></span>
+  </div>
+</div>
+<div class="SearchSnippet">
+  <div class="SearchSnippet-headerContainer"><h2><a href="/example.com/qux">example.com/qux</a></h2></div>
+  <p class="SearchSnippet-synopsis">Package qux does other things.</p>
+  <div class="SearchSnippet-infoLabel">
+    <a href="/example.com/qux?tab=importedby"><span>Imported by </span><strong>5</strong></a>
+    <span><strong>v0.1.0</strong> published on <span data-test-id="snippet-published"><strong>Mar 4, 2020</strong></span></span>
+    <span data-test-id="snippet-license"><a href="/example.com/qux?tab=licenses">BSD-3-Clause</a></span>
+  </div>
+</div>
+</div></body></html>`
+
+func TestClient_Search(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			rw.Write([]byte(`<div class="SearchResults"></div>`))
+			return
+		}
+		rw.Write([]byte(searchSnippetsHTML))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		results, err := client.Search(SearchRequest{Query: "foo", Limit: 10})
+		assert.NoError(t, err)
+		assert.Len(t, results.Results, 2)
+
+		first := results.Results[0]
+		assert.Equal(t, "github.com/foo/bar/baz", first.Package)
+		assert.Equal(t, "Package baz does things.", first.Synopsis)
+		assert.Equal(t, "2021-01-02", first.Published)
+		assert.Equal(t, 1234, first.ImportedBy)
+		assert.Equal(t, "MIT", first.License)
+		assert.Equal(t, "https://github.com/foo/bar", first.GitRepository)
+
+		assert.Equal(t, "example.com/qux", results.Results[1].Package)
+		assert.Equal(t, "", results.Results[1].GitRepository)
+	})
+}
+
+func TestClient_DescribePackageLicense(t *testing.T) {
+	cases := []struct {
+		name             string
+		html             string
+		expectLicense    string
+		expectDetailsURL string
+	}{
+		{
+			name:             "license with link",
+			html:             `<div data-test-id="UnitHeader-licenses">License: <a href="/somepackage?tab=licenses#lic-0">MIT</a></div>`,
+			expectLicense:    "MIT",
+			expectDetailsURL: "/somepackage?tab=licenses#lic-0",
+		},
+		{
+			name:             "relative fragment link",
+			html:             `<div data-test-id="UnitHeader-licenses">License: <a href="#lic-0">MIT</a></div>`,
+			expectLicense:    "MIT",
+			expectDetailsURL: "/somepackage#lic-0",
+		},
+		{
+			name:          "no license detected",
+			html:          `<div data-test-id="UnitHeader-licenses">License: <span>None detected</span></div>`,
+			expectLicense: "",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			html := c.html + `<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				rw.Write([]byte(html))
+			}, func(addr string) {
+				client := New(WithBaseURL("http://" + addr))
+				pkg, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+				assert.NoError(t, err)
+				assert.Equal(t, c.expectLicense, pkg.License)
+				expectDetailsURL := ""
+				if c.expectDetailsURL != "" {
+					expectDetailsURL = "http://" + addr + c.expectDetailsURL
+				}
+				assert.Equal(t, expectDetailsURL, pkg.LicenseDetailsURL)
+			})
+		})
+	}
+}
+
+func TestClient_WithOnError(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(500)
+	}, func(addr string) {
+		var gotErr error
+		var gotURL string
+		client := New(WithBaseURL("http://"+addr), WithOnError(func(err error, url string) {
+			gotErr = err
+			gotURL = url
+		}))
+		_, err := client.Versions(VersionsRequest{Package: "somepackage"})
+		assert.Error(t, err)
+		assert.EqualError(t, gotErr, "Internal Server Error")
+		assert.Equal(t, "http://"+addr+"/somepackage?tab=versions", gotURL)
+	})
+}
+
+func TestClient_DescribePackageMetaDescription(t *testing.T) {
+	cases := []struct {
+		name   string
+		head   string
+		expect string
+	}{
+		{
+			name:   "description meta tag",
+			head:   `<meta property="og:description" content="og summary"><meta name="description" content=" plain summary ">`,
+			expect: "plain summary",
+		},
+		{
+			name:   "og:description only",
+			head:   `<meta property="og:description" content="og summary">`,
+			expect: "og summary",
+		},
+		{
+			name:   "no meta tags",
+			expect: "",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				rw.Write([]byte(`<html><head>` + c.head + `</head><body><div class="UnitHeader-titleHeading">Heading</div><div>package</div></body></html>`))
+			}, func(addr string) {
+				client := New(WithBaseURL("http://" + addr))
+				pkg, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+				assert.NoError(t, err)
+				assert.Equal(t, c.expect, pkg.MetaDescription)
+			})
+		})
+	}
+}
+
+func TestClient_SprinkleMetaDescriptionFallback(t *testing.T) {
+	client := New()
+	p := &Package{Package: "example.com/foo", MetaDescription: "a summary"}
+	assert.NoError(t, client.Sprinkle(p))
+	assert.Equal(t, "a summary", p.Synopsis)
+
+	assert.EqualError(t, client.Sprinkle(&Package{Package: "example.com/foo"}), "no repository URL available")
+}
+
+const symbolDocHTML = `
+<html><body><div class="Documentation-content">
+<div class="Documentation-function">
+  <h4 tabindex="-1" id="New" data-kind="function" class="Documentation-functionHeader">func <a href="#New">New</a></h4>
+  <div class="Documentation-declaration"><pre>func New(options ...func(c *client)) *client</pre></div>
+  <p>New creates a client.</p>
+  <p>It takes options.</p>
+</div>
+<div class="Documentation-type">
+  <h4 tabindex="-1" id="Client" data-kind="type" class="Documentation-typeHeader">type <a href="#Client">Client</a></h4>
+  <div class="Documentation-declaration"><pre>type Client struct {
+	BaseURL string
+}</pre></div>
+  <p>Client talks to pkg.go.dev.</p>
+  <div class="Documentation-typeMethod">
+    <h4 tabindex="-1" id="Client.Search" data-kind="method" class="Documentation-typeMethodHeader">func (*Client) <a href="#Client.Search">Search</a></h4>
+    <div class="Documentation-declaration"><pre>func (c *Client) Search(q string) error</pre></div>
+    <p>Search searches.</p>
+  </div>
+</div>
+</div></body></html>`
+
+func TestClient_Symbol(t *testing.T) {
+	cases := []struct {
+		name              string
+		symbol            string
+		httpCode          int
+		expectSymbol      Symbol
+		expectErrContains string
+	}{
+		{
+			name:   "function",
+			symbol: "New",
+			expectSymbol: Symbol{
+				Name:        "New",
+				Kind:        "function",
+				Declaration: "func New(options ...func(c *client)) *client",
+				Synopsis:    "New creates a client.",
+			},
+		},
+		{
+			name:   "type",
+			symbol: "Client",
+			expectSymbol: Symbol{
+				Name:        "Client",
+				Kind:        "type",
+				Declaration: "type Client struct {\n\tBaseURL string\n}",
+				Synopsis:    "Client talks to pkg.go.dev.",
+			},
+		},
+		{
+			name:   "method",
+			symbol: "Client.Search",
+			expectSymbol: Symbol{
+				Name:        "Client.Search",
+				Kind:        "method",
+				Declaration: "func (c *Client) Search(q string) error",
+				Synopsis:    "Search searches.",
+			},
+		},
+		{
+			name:              "missing symbol",
+			symbol:            "Missing",
+			expectErrContains: "not found on pkg.go.dev",
+		},
+		{
+			name:              "missing package",
+			symbol:            "New",
+			httpCode:          404,
+			expectErrContains: "not found on pkg.go.dev",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				if c.httpCode != 0 {
+					rw.WriteHeader(c.httpCode)
+					return
+				}
+				rw.Write([]byte(symbolDocHTML))
+			}, func(addr string) {
+				client := New(WithBaseURL("http://" + addr))
+				sym, err := client.Symbol(context.Background(), "somepackage", c.symbol)
+				if c.expectErrContains != "" {
+					assert.ErrorContains(t, err, c.expectErrContains)
+					return
+				}
+				assert.NoError(t, err)
+				c.expectSymbol.Package = "somepackage"
+				c.expectSymbol.URL = "http://" + addr + "/somepackage#" + c.symbol
+				assert.Equal(t, c.expectSymbol, *sym)
+			})
+		})
+	}
+}
+
+func TestSynopsisSource_String(t *testing.T) {
+	assert.Equal(t, "Unknown", SynopsisSourceUnknown.String())
+	assert.Equal(t, "Repository", SynopsisSourceRepository.String())
+	assert.Equal(t, "MetaTag", SynopsisSourceMetaTag.String())
+	assert.Equal(t, "SearchSnippet", SynopsisSourceSearchSnippet.String())
+	assert.Equal(t, "DocOverview", SynopsisSourceDocOverview.String())
+	assert.Equal(t, "Unknown", SynopsisSource(42).String())
+}
+
+func TestClient_DescribePackageDocOverview(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<html><head><meta name="description" content="meta summary"></head><body>
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div>
+<section class="Documentation-overview"><h3>Overview</h3><p> Package foo does things. </p><p>More details.</p></section>
+</body></html>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Package foo does things.", pkg.Synopsis)
+		assert.Equal(t, SynopsisSourceDocOverview, pkg.SynopsisSource)
+	})
+}
+
+func TestClient_SprinkleSynopsisPriority(t *testing.T) {
+	client := New()
+
+	p := &Package{Package: "example.com/foo", MetaDescription: "meta summary"}
+	assert.NoError(t, client.Sprinkle(p))
+	assert.Equal(t, "meta summary", p.Synopsis)
+	assert.Equal(t, SynopsisSourceMetaTag, p.SynopsisSource)
+
+	p = &Package{
+		Package:         "example.com/foo",
+		MetaDescription: "meta summary",
+		Synopsis:        "doc summary",
+		SynopsisSource:  SynopsisSourceDocOverview,
+	}
+	assert.NoError(t, client.Sprinkle(p))
+	assert.Equal(t, "doc summary", p.Synopsis)
+	assert.Equal(t, SynopsisSourceDocOverview, p.SynopsisSource)
+
+	assert.NoError(t, client.Sprinkle(p, SprinkleForce))
+	assert.Equal(t, "meta summary", p.Synopsis)
+	assert.Equal(t, SynopsisSourceMetaTag, p.SynopsisSource)
+}
+
+func TestSearchResults_SortBy(t *testing.T) {
+	newResults := func() *SearchResults {
+		return &SearchResults{Results: []SearchResult{
+			{Package: "b", ImportedBy: 10, Published: "2021-01-01"},
+			{Package: "c", ImportedBy: 5, Published: "2022-01-01"},
+			{Package: "a", ImportedBy: 10, Published: "2020-01-01"},
+		}}
+	}
+	packages := func(r *SearchResults) []string {
+		var pkgs []string
+		for _, result := range r.Results {
+			pkgs = append(pkgs, result.Package)
+		}
+		return pkgs
+	}
+	cases := []struct {
+		field     string
+		ascending bool
+		expect    []string
+		expectErr error
+	}{
+		{field: "importedby", ascending: true, expect: []string{"c", "b", "a"}},
+		{field: "importedby", ascending: false, expect: []string{"b", "a", "c"}},
+		{field: "published", ascending: true, expect: []string{"a", "b", "c"}},
+		{field: "Published", ascending: false, expect: []string{"c", "b", "a"}},
+		{field: "package", ascending: true, expect: []string{"a", "b", "c"}},
+		{field: "package", ascending: false, expect: []string{"c", "b", "a"}},
+		{field: "stars", expect: []string{"b", "c", "a"}, expectErr: ErrUnknownSortField},
+	}
+	for _, c := range cases {
+		t.Run(c.field, func(t *testing.T) {
+			results := newResults()
+			err := results.SortBy(c.field, c.ascending)
+			if c.expectErr != nil {
+				assert.ErrorIs(t, err, c.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, c.expect, packages(results))
+		})
+	}
+}
+
+func TestSearchResults_FilterMap(t *testing.T) {
+	results := &SearchResults{Results: []SearchResult{
+		{Package: "a", ImportedBy: 10},
+		{Package: "b", ImportedBy: 5},
+		{Package: "c", ImportedBy: 20},
+	}}
+
+	popular := results.Filter(func(r SearchResult) bool { return r.ImportedBy >= 10 })
+	assert.Equal(t, []SearchResult{{Package: "a", ImportedBy: 10}, {Package: "c", ImportedBy: 20}}, popular.Results)
+
+	prefixed := results.Map(func(r SearchResult) SearchResult {
+		r.Package = "example.com/" + r.Package
+		return r
+	})
+	assert.Equal(t, "example.com/b", prefixed.Results[1].Package)
+
+	assert.Equal(t, "b", results.Results[1].Package, "the original is left unchanged")
+	assert.Len(t, results.Results, 3)
+	assert.Empty(t, results.Filter(func(SearchResult) bool { return false }).Results)
+}
+
+const versionsPlainHTML = `
+<html><body><div class="Versions-list">
+  <div class="Version-major">v2</div>
+  <div class="Version-tag"><a class="js-versionLink" href="/somepackage@v2.1.0">v2.1.0</a></div>
+  <div class="Version-commitTime">Mar 4, 2021</div>
+  <div class="Version-major"></div>
+  <div class="Version-tag"><a class="js-versionLink" href="/somepackage@v2.0.0">v2.0.0</a></div>
+  <div class="Version-commitTime">Feb 3, 2021</div>
+  <div class="Version-major">v1</div>
+  <div class="Version-tag"><a class="js-versionLink" href="/somepackage@v1.0.0">v1.0.0</a></div>
+  <div class="Version-commitTime">Jan 2, 2020</div>
+</div></body></html>`
+
+const versionsDetailsHTML = `
+<html><body><div class="Versions-list">
+  <div class="Version-major">v2</div>
+  <div class="Version-tag"><a class="js-versionLink" href="/somepackage@v2.1.0">v2.1.0</a></div>
+  <div class="Version-details">
+    <details><summary class="Version-summary"><span class="Version-dot"></span> Mar 4, 2021 </summary>
+      <div class="Version-summary">Changes in this version: <span>+ func Foo</span></div>
+    </details>
+  </div>
+  <div class="Version-tag"><a class="js-versionLink" href="/somepackage@v2.0.0">v2.0.0</a></div>
+  <div class="Version-commitTime">Feb 3, 2021</div>
+  <div class="Version-major">v1</div>
+  <div class="Version-tag"><a class="js-versionLink" href="/somepackage@v1.0.0">v1.0.0</a></div>
+  <div class="Version-details">
+    <details><summary class="Version-summary"><span class="Version-dot"></span> Jan 2, 2020 </summary></details>
+  </div>
+</div></body></html>`
+
+func TestClient_Versions(t *testing.T) {
+	expectVersions := []Version{
+		{MajorVersion: "v2", FullVersion: "v2.1.0", Date: "2021-03-04"},
+		{MajorVersion: "v2", FullVersion: "v2.0.0", Date: "2021-02-03"},
+		{MajorVersion: "v1", FullVersion: "v1.0.0", Date: "2020-01-02"},
+	}
+	cases := []struct {
+		name              string
+		html              string
+		httpCode          int
+		expectVersions    []Version
+		expectErrContains string
+	}{
+		{
+			name:           "plain rows",
+			html:           versionsPlainHTML,
+			expectVersions: expectVersions,
+		},
+		{
+			name:           "rows with change summaries",
+			html:           versionsDetailsHTML,
+			expectVersions: expectVersions,
+		},
+		{
+			name:              "returns error on 404",
+			httpCode:          404,
+			expectErrContains: "not found on pkg.go.dev",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				if c.httpCode != 0 {
+					rw.WriteHeader(c.httpCode)
+					return
+				}
+				rw.Write([]byte(c.html))
+			}, func(addr string) {
+				client := New(WithBaseURL("http://" + addr))
+				versions, err := client.Versions(VersionsRequest{Package: "somepackage"})
+				if c.expectErrContains != "" {
+					assert.ErrorContains(t, err, c.expectErrContains)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, "somepackage", versions.Package)
+				assert.Equal(t, c.expectVersions, versions.Versions)
+			})
+		})
+	}
+}
+
+func TestClient_DescribePackageSourceCodeURL(t *testing.T) {
+	cases := []struct {
+		name   string
+		html   string
+		expect string
+	}{
+		{
+			name: "view all link",
+			html: `<div class="UnitFiles"><h2>Source Files</h2>
+<span class="UnitFiles-titleLink"><a href="https://github.com/foo/bar/tree/v1.0.0/baz">View all Source files</a></span>
+<ul class="UnitFiles-fileList"><li><a href="https://github.com/foo/bar/blob/v1.0.0/baz/baz.go">baz.go</a></li></ul></div>`,
+			expect: "https://github.com/foo/bar/tree/v1.0.0/baz",
+		},
+		{
+			name: "file links only",
+			html: `<div class="UnitFiles"><h2>Source Files</h2>
+<ul class="UnitFiles-fileList"><li><a href="https://github.com/foo/bar/blob/v1.0.0/baz/baz.go">baz.go</a></li></ul></div>`,
+			expect: "https://github.com/foo/bar/tree/v1.0.0/baz",
+		},
+		{
+			name:   "no source files",
+			expect: "",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				rw.Write([]byte(`<div class="UnitHeader-titleHeading">Heading</div><div>package</div>` + c.html))
+			}, func(addr string) {
+				client := New(WithBaseURL("http://" + addr))
+				pkg, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+				assert.NoError(t, err)
+				assert.Equal(t, c.expect, pkg.SourceCodeURL)
+			})
+		})
+	}
+}
+
+func TestClient_CollectStats(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			rw.Write([]byte(`<div class="SearchResults"></div>`))
+			return
+		}
+		rw.Write([]byte(searchSnippetsHTML))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+
+		results, err := client.Search(SearchRequest{Query: "foo", Limit: 10})
+		assert.NoError(t, err)
+		assert.Nil(t, results.Stats)
+
+		results, err = client.Search(SearchRequest{Query: "foo", Limit: 10, CollectStats: true})
+		assert.NoError(t, err)
+		stats := results.Stats
+		assert.Equal(t, 2, stats.PagesFetched)
+		assert.Equal(t, int64(len(searchSnippetsHTML)+len(`<div class="SearchResults"></div>`)), stats.BytesDownloaded)
+		assert.Len(t, stats.URLDurations, 2)
+		assert.Contains(t, stats.URLDurations, "http://"+addr+"/search?q=foo&page=1")
+		assert.Greater(t, stats.Duration, time.Duration(0))
+
+		importedBy, err := client.ImportedBy(ImportedByRequest{Package: "somepackage", CollectStats: true})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, importedBy.Stats.PagesFetched)
+	})
+}
+
+func TestClient_WithMaxPages(t *testing.T) {
+	var pagesVisited int
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		pagesVisited++
+		rw.Write([]byte(searchSnippetsHTML))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://"+addr), WithMaxPages(3))
+		results, err := client.Search(SearchRequest{Query: "foo", Limit: 100})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, pagesVisited)
+		assert.Len(t, results.Results, 6)
+	})
+}
+
+func TestClient_WithSelectors(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<html><body>
+<div class="u-breakWord">old</div>
+<li class="Importer">new</li>
+<div class="VersionsTable">
+  <span class="Major">v1</span>
+  <span class="Tag"><a class="Link">v1.0.0</a></span>
+  <span class="Date">Jan 2, 2020</span>
+</div>
+</body></html>`))
+	}, func(addr string) {
+		selectors := DefaultSelectors()
+		selectors.ImportedBy = ".Importer"
+		selectors.VersionsList = ".VersionsTable"
+		selectors.VersionMajor = ".Major"
+		selectors.VersionTag = ".Tag"
+		selectors.VersionLink = ".Link"
+		selectors.VersionCommitTime = ".Date"
+		client := New(WithBaseURL("http://"+addr), WithSelectors(selectors))
+
+		importedBy, err := client.ImportedBy(ImportedByRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"new"}, importedBy.ImportedBy)
+
+		versions, err := client.Versions(VersionsRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.Equal(t, []Version{{MajorVersion: "v1", FullVersion: "v1.0.0", Date: "2020-01-02"}}, versions.Versions)
+	})
+}
+
+func TestClient_SearchRetracted(t *testing.T) {
+	html := strings.Replace(searchSnippetsHTML,
+		`<h2><a href="/example.com/qux">example.com/qux</a></h2>`,
+		`<h2><a href="/example.com/qux">example.com/qux</a></h2><span class="go-Chip go-Chip--alert">retracted</span>`, 1)
+	cases := []struct {
+		name           string
+		req            SearchRequest
+		expectPackages []string
+		expectErr      bool
+	}{
+		{
+			name:           "all results by default",
+			req:            SearchRequest{Query: "foo", Limit: 10},
+			expectPackages: []string{"github.com/foo/bar/baz", "example.com/qux"},
+		},
+		{
+			name:           "exclude retracted",
+			req:            SearchRequest{Query: "foo", Limit: 10, ExcludeRetracted: true},
+			expectPackages: []string{"github.com/foo/bar/baz"},
+		},
+		{
+			name:           "only retracted",
+			req:            SearchRequest{Query: "foo", Limit: 10, OnlyRetracted: true},
+			expectPackages: []string{"example.com/qux"},
+		},
+		{
+			name:      "both is an error",
+			req:       SearchRequest{Query: "foo", Limit: 10, ExcludeRetracted: true, OnlyRetracted: true},
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("page") != "1" {
+					rw.Write([]byte(`<div class="SearchResults"></div>`))
+					return
+				}
+				rw.Write([]byte(html))
+			}, func(addr string) {
+				client := New(WithBaseURL("http://" + addr))
+				results, err := client.Search(c.req)
+				if c.expectErr {
+					assert.Error(t, err)
+					return
+				}
+				assert.NoError(t, err)
+				var pkgs []string
+				for _, result := range results.Results {
+					pkgs = append(pkgs, result.Package)
+					assert.Equal(t, result.Package == "example.com/qux", result.Retracted)
+				}
+				assert.Equal(t, c.expectPackages, pkgs)
+			})
+		})
+	}
+}
+
+func TestClient_DescribePackageVersionQuery(t *testing.T) {
+	const pseudoVersion = "v0.0.0-20210107192922-496545a6307b"
+	var requestedPaths []string
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		requestedPaths = append(requestedPaths, r.URL.Path)
+		switch r.URL.Path {
+		case "/proxy/github.com/foo/bar/@v/master.info":
+			rw.Write([]byte(`{"Version":"` + pseudoVersion + `","Time":"2021-01-07T19:29:22Z"}`))
+		case "/github.com/foo/bar/baz@" + pseudoVersion, "/github.com/foo/bar/baz@v1.0.0", "/example.com/nomod@main":
+			rw.Write([]byte(`<div data-test-id="UnitHeader-version"><a>Version: v0.0.0-...-496545a</a></div>
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`))
+		default:
+			rw.WriteHeader(404)
+		}
+	}, func(addr string) {
+		client := New(WithBaseURL("http://"+addr), WithProxyURL("http://"+addr+"/proxy"))
+
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "github.com/foo/bar/baz", Version: "master"})
+		assert.NoError(t, err)
+		assert.Equal(t, "master", pkg.RequestedVersion)
+		assert.Equal(t, pseudoVersion, pkg.Version)
+		assert.Equal(t, []string{
+			"/proxy/github.com/foo/bar/baz/@v/master.info",
+			"/proxy/github.com/foo/bar/@v/master.info",
+			"/github.com/foo/bar/baz@" + pseudoVersion,
+		}, requestedPaths)
+
+		requestedPaths = nil
+		pkg, err = client.DescribePackage(DescribePackageRequest{Package: "github.com/foo/bar/baz", Version: "v1.0.0"})
+		assert.NoError(t, err)
+		assert.Equal(t, "v1.0.0", pkg.RequestedVersion)
+		assert.Equal(t, []string{"/github.com/foo/bar/baz@v1.0.0"}, requestedPaths)
+
+		// falls back to pkg.go.dev's own resolution when the proxy doesn't know the ref
+		pkg, err = client.DescribePackage(DescribePackageRequest{Package: "example.com/nomod", Version: "main"})
+		assert.NoError(t, err)
+		assert.Equal(t, "main", pkg.RequestedVersion)
+		assert.Equal(t, "v0.0.0-...-496545a", pkg.Version)
+	})
+
+	t.Run("cancels the proxy lookup", func(t *testing.T) {
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}, func(addr string) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			_, err := New(WithProxyURL("http://"+addr+"/proxy")).resolveRef(ctx, "github.com/foo/bar", "master")
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.Less(t, time.Since(start), 2*time.Second)
+		})
+	})
+}
+
+func TestClient_Metrics(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("tab") == "versions" {
+			rw.WriteHeader(500)
+			return
+		}
+		rw.Write([]byte(`<div class="u-breakWord">foo</div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		assert.Equal(t, ClientMetrics{}, client.Metrics())
+
+		_, err := client.ImportedBy(ImportedByRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		_, err = client.Versions(VersionsRequest{Package: "somepackage"})
+		assert.Error(t, err)
+
+		metrics := client.Metrics()
+		assert.Equal(t, uint64(2), metrics.TotalRequests)
+		assert.Equal(t, uint64(1), metrics.Errors)
+		assert.Equal(t, uint64(0), metrics.CacheHits)
+		assert.Greater(t, metrics.TotalDuration, uint64(0))
+
+		client.ResetMetrics()
+		assert.Equal(t, ClientMetrics{}, client.Metrics())
+	})
+}
+
+func TestClient_LicenseReport(t *testing.T) {
+	licenses := map[string]string{
+		"/a": "MIT",
+		"/b": "Apache-2.0, MIT, Apache-2.0, MIT",
+		"/c": "GPL-3.0",
+		"/d": "",
+	}
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		license, ok := licenses[r.URL.Path]
+		if !ok {
+			rw.WriteHeader(404)
+			return
+		}
+		rw.Write([]byte(`<div data-test-id="UnitHeader-licenses"><a>` + license + `</a></div>
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		report, err := client.LicenseReport(context.Background(), []string{"a", "b", "c", "d", "missing"}, BatchOptions{Concurrency: 2})
+		assert.ErrorContains(t, err, "describing 'missing'")
+		assert.Equal(t, &LicenseReport{
+			Groups: []LicenseGroup{
+				{License: "Apache-2.0", Packages: []string{"b"}},
+				{License: "GPL-3.0", Copyleft: true, Packages: []string{"c"}},
+				{License: "MIT", Packages: []string{"a", "b"}},
+			},
+			Undetermined: []string{"d", "missing"},
+		}, report)
+
+		buf := &bytes.Buffer{}
+		assert.NoError(t, report.WriteCSV(buf))
+		assert.Equal(t, `license,copyleft,package
+Apache-2.0,false,b
+GPL-3.0,true,c
+MIT,false,a
+MIT,false,b
+,false,d
+,false,missing
+`, buf.String())
+	})
+
+	t.Run("cancels the describe requests", func(t *testing.T) {
+		arrived := make(chan struct{}, 1)
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			arrived <- struct{}{}
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}, func(addr string) {
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				<-arrived
+				cancel()
+			}()
+			start := time.Now()
+			_, err := New(WithBaseURL("http://"+addr)).LicenseReport(ctx, []string{"a"}, BatchOptions{})
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Less(t, time.Since(start), 2*time.Second)
+		})
+	})
+}
+
+func TestPackage_IsModuleV2Plus(t *testing.T) {
+	cases := []struct {
+		pkg    string
+		expect bool
+	}{
+		{pkg: "github.com/some/mod", expect: false},
+		{pkg: "github.com/some/mod/v1/pkg", expect: false},
+		{pkg: "github.com/some/mod/v2", expect: true},
+		{pkg: "github.com/some/mod/v2/pkg", expect: true},
+		{pkg: "github.com/some/mod/v10/pkg", expect: true},
+		{pkg: "github.com/some/mod/v02/pkg", expect: false},
+		{pkg: "github.com/some/mod/v2beta/pkg", expect: false},
+		{pkg: "gopkg.in/mgo.v2/bson", expect: true},
+		{pkg: "gopkg.in/check.v1", expect: false},
+	}
+	for _, c := range cases {
+		t.Run(c.pkg, func(t *testing.T) {
+			p := &Package{Package: c.pkg}
+			assert.Equal(t, c.expect, p.IsModuleV2Plus())
+		})
+	}
+}
+
+func TestClient_DescribePackageModuleVersion(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<div data-test-id="UnitHeader-version"><a>Version: v2.3.1</a></div>
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "github.com/some/mod/v2/pkg"})
+		assert.NoError(t, err)
+		assert.Equal(t, "v2.3.1", pkg.Version)
+		assert.Equal(t, "v2", pkg.ModuleVersion)
+		assert.True(t, pkg.IsModuleV2Plus())
+	})
+}
+
+func TestClient_Snapshot(t *testing.T) {
+	unitHTML := `<div class="UnitHeader">
+<div class="UnitHeader-titleHeading">Heading</div><span class="go-Chip">package</span><span class="go-Chip">deprecated</span>
+<div data-test-id="UnitHeader-version"><a>Version: v1.2.0</a></div>
+<div data-test-id="UnitHeader-commitTime">Published: Feb 3, 2021</div>
+<div data-test-id="UnitHeader-licenses"><a>Apache-2.0, MIT</a></div>
+<a data-test-id="UnitHeader-imports">Imports: 7</a>
+<a data-test-id="UnitHeader-importedby">Imported by: 1,234</a>
+<div class="Vuln-alert"><a href="/vuln/GO-2022-0123">GO-2022-0123</a> <a href="/vuln/GO-2022-0123">details</a></div>
+</div>
+<div class="UnitMeta"><ul>
+  <li><img alt="checked"/></li>
+  <li><img alt="checked"/></li>
+  <li><img alt="checked"/></li>
+  <li><img alt="unchecked"/></li>
+</ul></div>`
+
+	t.Run("happy case", func(t *testing.T) {
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("tab") == "versions" {
+				rw.Write([]byte(versionsPlainHTML))
+				return
+			}
+			rw.Write([]byte(unitHTML))
+		}, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			snap, err := client.Snapshot("somepackage")
+			assert.NoError(t, err)
+			assert.Equal(t, &Snapshot{
+				Package:          "somepackage",
+				Version:          "v1.2.0",
+				Published:        "2021-02-03",
+				ImportedByCount:  1234,
+				ImportsCount:     7,
+				Licenses:         []string{"Apache-2.0", "MIT"},
+				HasTaggedVersion: true,
+				Deprecated:       true,
+				Vulnerabilities:  []string{"GO-2022-0123"},
+			}, snap)
+		})
+	})
+
+	t.Run("degrades on partial failures", func(t *testing.T) {
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("tab") == "versions" {
+				rw.Write([]byte(versionsPlainHTML))
+				return
+			}
+			rw.WriteHeader(500)
+		}, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			snap, err := client.Snapshot("somepackage")
+			assert.NoError(t, err)
+			assert.Equal(t, "v2.1.0", snap.Version)
+			assert.Equal(t, "2021-03-04", snap.Published)
+			assert.Len(t, snap.Errors, 1)
+			assert.Equal(t, "unit page", snap.Errors[0].Field)
+		})
+	})
+
+	t.Run("fails when nothing could be fetched", func(t *testing.T) {
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(404)
+		}, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			_, err := client.Snapshot("somepackage")
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	})
+}
+
+func TestClient_DescribeModulePackages(t *testing.T) {
+	moduleHTML := `<div class="UnitHeader-titleHeading">mod</div><span class="go-Chip">module</span><span class="go-Chip">package</span>
+<table class="UnitDirectories"><tbody>
+  <tr><td><a href="/example.com/mod/foo">foo</a></td><td class="UnitDirectories-desktopSynopsis">Package foo does foo.</td></tr>
+  <tr><td><a href="/example.com/mod/internal/bar">internal/bar</a></td></tr>
+  <tr><td><a href="/example.com/mod/nested">nested</a> <span class="go-Chip">module</span></td></tr>
+  <tr><td><a href="/example.com/mod/missing">missing</a></td></tr>
+</tbody></table>`
+	packageHTML := `<div class="UnitHeader-titleHeading">pkg</div><span class="go-Chip">package</span>
+<div data-test-id="UnitHeader-version"><a>Version: v1.0.0</a></div>`
+
+	handler := func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/mod":
+			rw.Write([]byte(moduleHTML))
+		case "/example.com/mod/missing":
+			rw.WriteHeader(404)
+		default:
+			rw.Write([]byte(packageHTML))
+		}
+	}
+
+	t.Run("lists directories", func(t *testing.T) {
+		withHTTPServer("/", handler, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			dirs, err := client.Directories(context.Background(), "example.com/mod")
+			assert.NoError(t, err)
+			assert.Equal(t, []Directory{
+				{Path: "example.com/mod/foo", Synopsis: "Package foo does foo."},
+				{Path: "example.com/mod/internal/bar"},
+				{Path: "example.com/mod/nested", IsModule: true},
+				{Path: "example.com/mod/missing"},
+			}, dirs)
+		})
+	})
+
+	t.Run("describes packages in listing order", func(t *testing.T) {
+		withHTTPServer("/", handler, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			pkgs, errs := client.DescribeModulePackages(context.Background(), "example.com/mod", BatchOptions{
+				Concurrency:          2,
+				ExcludeNestedModules: true,
+				ExcludeInternal:      true,
+			})
+			assert.Len(t, pkgs, 3)
+			assert.Len(t, errs, 3)
+			assert.Equal(t, "example.com/mod", pkgs[0].Package)
+			assert.Equal(t, "example.com/mod/foo", pkgs[1].Package)
+			assert.Equal(t, "v1.0.0", pkgs[1].Version)
+			assert.NoError(t, errs[0])
+			assert.NoError(t, errs[1])
+			assert.Nil(t, pkgs[2])
+			assert.Error(t, errs[2])
+		})
+	})
+
+	t.Run("returns a single error when the listing fails", func(t *testing.T) {
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(404)
+		}, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			pkgs, errs := client.DescribeModulePackages(context.Background(), "example.com/mod", BatchOptions{})
+			assert.Nil(t, pkgs)
+			assert.Len(t, errs, 1)
+			assert.ErrorIs(t, errs[0], ErrNotFound)
+		})
+	})
+
+	t.Run("cancels the describe requests", func(t *testing.T) {
+		arrived := make(chan struct{}, 1)
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/example.com/mod" {
+				rw.Write([]byte(moduleHTML))
+				return
+			}
+			select {
+			case arrived <- struct{}{}:
+			default:
+			}
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}, func(addr string) {
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				<-arrived
+				cancel()
+			}()
+			start := time.Now()
+			_, errs := New(WithBaseURL("http://"+addr)).DescribeModulePackages(ctx, "example.com/mod", BatchOptions{})
+			for _, err := range errs {
+				assert.ErrorIs(t, err, context.Canceled)
+			}
+			assert.Less(t, time.Since(start), 2*time.Second)
+		})
+	})
+}
+
+func TestImports_All(t *testing.T) {
+	imports := &Imports{
+		Imports:                []string{"github.com/foo/bar", "fmt", "example.com/baz"},
+		StandardLibraryImports: []string{"strings", "fmt"},
+	}
+	assert.Equal(t, []string{"example.com/baz", "fmt", "github.com/foo/bar", "strings"}, imports.All())
+	assert.Equal(t, 4, imports.Count())
+	assert.Equal(t, 3, imports.ExternalImportCount())
+	assert.Equal(t, 2, imports.StdlibImportCount())
+	assert.Equal(t, 5, imports.TotalImportCount())
+
+	empty := &Imports{}
+	assert.Equal(t, []string{}, empty.All())
+	assert.Equal(t, 0, empty.Count())
+	assert.Equal(t, 0, empty.TotalImportCount())
+}
+
+func TestClient_TopPackages(t *testing.T) {
+	snippet := func(pkg string, importedBy int) string {
+		return fmt.Sprintf(`<div class="SearchResults"><div class="SearchSnippet">
+  <div class="SearchSnippet-headerContainer"><h2><a href="/%[1]s">%[1]s</a></h2></div>
+  <div class="SearchSnippet-infoLabel">
+    <a href="/%[1]s?tab=importedby"><strong>%[2]d</strong></a>
+    <span><strong>v1.0.0</strong> published on <span data-test-id="snippet-published"><strong>Jan 2, 2021</strong></span></span>
+  </div>
+</div></div>`, pkg, importedBy)
+	}
+
+	t.Run("ranks seed packages when the empty search yields nothing", func(t *testing.T) {
+		counts := map[string]int{
+			"github.com/google/uuid": 300,
+			"github.com/pkg/errors":  200,
+			"github.com/spf13/cobra": 100,
+			"golang.org/x/sys/unix":  50,
+			"go.uber.org/zap":        10,
+		}
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query().Get("q")
+			if q == "" || r.URL.Query().Get("page") != "1" {
+				rw.Write([]byte(`<div class="SearchResults"></div>`))
+				return
+			}
+			rw.Write([]byte(snippet(q, counts[q])))
+		}, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			results, err := client.TopPackages(context.Background(), 3)
+			assert.NoError(t, err)
+			var pkgs []string
+			for _, result := range results.Results {
+				pkgs = append(pkgs, result.Package)
+			}
+			assert.Equal(t, []string{"github.com/google/uuid", "github.com/pkg/errors", "github.com/spf13/cobra"}, pkgs)
+		})
+	})
+
+	t.Run("uses the empty search when it has results", func(t *testing.T) {
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") != "1" {
+				rw.Write([]byte(`<div class="SearchResults"></div>`))
+				return
+			}
+			rw.Write([]byte(searchSnippetsHTML))
+		}, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			results, err := client.TopPackages(context.Background(), 10)
+			assert.NoError(t, err)
+			assert.Len(t, results.Results, 2)
+			assert.Equal(t, "github.com/foo/bar/baz", results.Results[0].Package)
+		})
+	})
+
+	t.Run("rejects a non-positive limit", func(t *testing.T) {
+		client := New()
+		_, err := client.TopPackages(context.Background(), 0)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("stops the searches when canceled", func(t *testing.T) {
+		var mu sync.Mutex
+		var requests int
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests++
+			mu.Unlock()
+			rw.Write([]byte(`<div class="SearchResults"></div>`))
+		}, func(addr string) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := New(WithBaseURL("http://"+addr)).TopPackages(ctx, 3)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Zero(t, requests)
+		})
+	})
+}
+
+func TestClient_DescribePackageLatest(t *testing.T) {
+	cases := []struct {
+		name          string
+		banner        string
+		expectLatest  bool
+		expectVersion string
+	}{
+		{
+			name:          "no banner",
+			expectLatest:  true,
+			expectVersion: "v1.0.0",
+		},
+		{
+			name:          "latest badge",
+			banner:        `<a data-test-id="UnitHeader-minorVersionBanner" href="/example.com/foo"><span>Latest</span></a>`,
+			expectLatest:  true,
+			expectVersion: "v1.0.0",
+		},
+		{
+			name:          "newer version banner with version",
+			banner:        `<a data-test-id="UnitHeader-minorVersionBanner" href="/example.com/foo@v1.4.2"><span>Go to latest</span></a>`,
+			expectVersion: "v1.4.2",
+		},
+		{
+			name:   "newer version banner without version",
+			banner: `<div data-test-id="UnitHeader-minorVersionBanner">There is a newer version of this module. <a href="/example.com/foo">Go to latest</a></div>`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				rw.Write([]byte(`<div data-test-id="UnitHeader-version"><a>Version: v1.0.0</a></div>` + c.banner +
+					`<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`))
+			}, func(addr string) {
+				client := New(WithBaseURL("http://" + addr))
+				pkg, err := client.DescribePackage(DescribePackageRequest{Package: "example.com/foo"})
+				assert.NoError(t, err)
+				assert.Equal(t, c.expectLatest, pkg.IsLatest)
+				assert.Equal(t, c.expectVersion, pkg.LatestVersion)
+			})
+		})
+	}
+}
+
+func TestVersion_ChangelogURL(t *testing.T) {
+	cases := []struct {
+		repository string
+		expect     string
+	}{
+		{"github.com/foo/bar", "https://github.com/foo/bar/releases/tag/v1.2.3"},
+		{"https://github.com/foo/bar.git", "https://github.com/foo/bar/releases/tag/v1.2.3"},
+		{"gitlab.com/foo/bar", "https://gitlab.com/foo/bar/-/tags/v1.2.3"},
+		{"codeberg.org/foo/bar", "https://codeberg.org/foo/bar/releases/tag/v1.2.3"},
+		{"example.com/foo/bar", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		v := Version{FullVersion: "v1.2.3", Repository: c.repository}
+		assert.Equal(t, c.expect, v.ChangelogURL(), c.repository)
+	}
+}
+
+func TestClient_VersionsRepository(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<div class="UnitMeta-repo"><a>github.com/foo/bar</a></div>` + versionsPlainHTML))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		versions, err := client.Versions(VersionsRequest{Package: "github.com/foo/bar"})
+		assert.NoError(t, err)
+		assert.Equal(t, "github.com/foo/bar", versions.Repository)
+		latest, ok := versions.Latest()
+		assert.True(t, ok)
+		assert.Equal(t, "https://github.com/foo/bar/releases/tag/"+latest.FullVersion, latest.ChangelogURL())
+	})
+}
+
+func TestClient_SearchLimits(t *testing.T) {
+	var pagesVisited []string
+	var mu sync.Mutex
+	handler := func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		pagesVisited = append(pagesVisited, r.URL.RawQuery)
+		mu.Unlock()
+		// every page holds the same two results
+		rw.Write([]byte(searchSnippetsHTML))
+	}
+
+	cases := []struct {
+		name          string
+		req           SearchRequest
+		expectResults int
+		expectPages   []string
+	}{
+		{
+			name:          "zero limit returns the first page",
+			req:           SearchRequest{Query: "foo"},
+			expectResults: 2,
+			expectPages:   []string{"q=foo&page=1"},
+		},
+		{
+			name:          "negative limit returns the first page",
+			req:           SearchRequest{Query: "foo", Limit: -1, MaxPages: 5},
+			expectResults: 2,
+			expectPages:   []string{"q=foo&page=1"},
+		},
+		{
+			name:          "limit is reached before MaxPages",
+			req:           SearchRequest{Query: "foo", Limit: 3, MaxPages: 5},
+			expectResults: 3,
+			expectPages:   []string{"q=foo&page=1", "q=foo&page=2"},
+		},
+		{
+			name:          "MaxPages stops before the limit",
+			req:           SearchRequest{Query: "foo", Limit: 100, MaxPages: 2},
+			expectResults: 4,
+			expectPages:   []string{"q=foo&page=1", "q=foo&page=2"},
+		},
+		{
+			name:          "Page fetches only that page",
+			req:           SearchRequest{Query: "foo", Limit: 10, Page: 3},
+			expectResults: 2,
+			expectPages:   []string{"q=foo&page=3"},
+		},
+		{
+			name:          "Page applies the limit",
+			req:           SearchRequest{Query: "foo", Limit: 1, Page: 1},
+			expectResults: 1,
+			expectPages:   []string{"q=foo&page=1"},
+		},
+		{
+			name:          "PageSize is passed on",
+			req:           SearchRequest{Query: "foo", Limit: 1, PageSize: 50},
+			expectResults: 1,
+			expectPages:   []string{"q=foo&page=1&limit=50"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pagesVisited = nil
+			withHTTPServer("/", handler, func(addr string) {
+				client := New(WithBaseURL("http://" + addr))
+				results, err := client.Search(c.req)
+				assert.NoError(t, err)
+				assert.Len(t, results.Results, c.expectResults)
+				assert.Equal(t, c.expectPages, pagesVisited)
+			})
+		})
+	}
+
+	for _, req := range []SearchRequest{
+		{Query: "foo", MaxPages: -1},
+		{Query: "foo", Page: -1},
+		{Query: "foo", PageSize: -1},
+		{Query: "foo", PageSize: 101},
+		{Query: "foo", ExcludeRetracted: true, OnlyRetracted: true},
+	} {
+		_, err := New().Search(req)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	}
+}
+
+func TestClient_ResolveDependents(t *testing.T) {
+	importers := map[string][]string{
+		"/a": {"b", "c"},
+		"/b": {"c", "d"},
+		"/c": {},
+		"/d": {"e", "a"},
+	}
+	handler := func(rw http.ResponseWriter, r *http.Request) {
+		list, ok := importers[r.URL.Path]
+		if !ok {
+			rw.WriteHeader(404)
+			return
+		}
+		for _, pkg := range list {
+			fmt.Fprintf(rw, `<div class="u-breakWord">%s</div>`, pkg)
+		}
+	}
+
+	t.Run("cancels the importedby requests", func(t *testing.T) {
+		arrived := make(chan struct{}, 1)
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			arrived <- struct{}{}
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}, func(addr string) {
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				<-arrived
+				cancel()
+			}()
+			start := time.Now()
+			_, err := New(WithBaseURL("http://"+addr)).ResolveDependents(ctx, "a", 1)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Less(t, time.Since(start), 2*time.Second)
+		})
+	})
+
+	t.Run("follows importers up to depth", func(t *testing.T) {
+		withHTTPServer("/", handler, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			graph, err := client.ResolveDependents(context.Background(), "a", 1)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string][]string{"a": {"b", "c"}}, graph)
+
+			graph, err = client.ResolveDependents(context.Background(), "a", 3)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string][]string{
+				"a": {"b", "c"},
+				"b": {"c", "d"},
+				"c": {},
+				"d": {"e", "a"},
+			}, graph)
+		})
+	})
+
+	t.Run("reports failed lookups with the partial graph", func(t *testing.T) {
+		withHTTPServer("/", handler, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			graph, err := client.ResolveDependents(context.Background(), "a", 4)
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.Len(t, graph, 4)
+		})
+	})
+
+	t.Run("stops at the node cap", func(t *testing.T) {
+		withHTTPServer("/", handler, func(addr string) {
+			client := New(WithBaseURL("http://"+addr), WithMaxGraphNodes(3))
+			graph, err := client.ResolveDependents(context.Background(), "a", 3)
+			assert.ErrorIs(t, err, ErrGraphTruncated)
+			assert.Equal(t, map[string][]string{
+				"a": {"b", "c"},
+				"b": {"c", "d"},
+				"c": {},
+			}, graph)
+		})
+	})
+
+	t.Run("rejects a non-positive depth", func(t *testing.T) {
+		_, err := New().ResolveDependents(context.Background(), "a", 0)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+}
+
+func TestClient_SearchConcurrent(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "3" {
+			rw.Write([]byte(`<div class="SearchResults"></div>`))
+			return
+		}
+		rw.Write([]byte(searchSnippetsHTML))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results, err := client.Search(SearchRequest{Query: "foo", Limit: 10, CollectStats: true})
+				assert.NoError(t, err)
+				assert.Len(t, results.Results, 4)
+				assert.Equal(t, 3, results.Stats.PagesFetched)
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestClient_SearchMissingInfo(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			rw.Write([]byte(`<div class="SearchResults"></div>`))
+			return
+		}
+		rw.Write([]byte(`<div class="SearchResults">
+<div class="SearchSnippet">
+  <div class="SearchSnippet-headerContainer"><h2><a href="/example.com/new">example.com/new</a></h2></div>
+  <div class="SearchSnippet-infoLabel"></div>
+</div>
+<div class="SearchSnippet">
+  <div class="SearchSnippet-headerContainer"><h2><a href="/example.com/odd">example.com/odd</a></h2></div>
+  <div class="SearchSnippet-infoLabel">
+    <span><strong>v0.0.1</strong> published on <span data-test-id="snippet-published"><strong>sometime</strong></span></span>
+  </div>
+</div>
+</div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		results, err := client.Search(SearchRequest{Query: "foo", Limit: 10})
+		assert.NoError(t, err)
+		assert.Len(t, results.Results, 2)
+
+		assert.Equal(t, "example.com/new", results.Results[0].Package)
+		assert.Empty(t, results.Results[0].Published)
+		assert.Empty(t, results.Results[0].PublishedRaw)
+		assert.Zero(t, results.Results[0].ImportedBy)
+		assert.Empty(t, results.Results[0].License)
+
+		assert.Empty(t, results.Results[1].Published)
+		assert.Equal(t, "sometime", results.Results[1].PublishedRaw)
+	})
+}
+
+func TestClient_DescribePackageSymbolCounts(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<div class="UnitHeader-titleHeading">Heading</div><div>package</div>
+<div class="Documentation-index"><ul>
+  <li><a href="#pkg-constants">Constants</a></li>
+  <li><a href="#pkg-variables">Variables</a></li>
+  <li class="Documentation-indexFunction"><a href="#Parse">func Parse(s string) (*Foo, error)</a></li>
+  <li class="Documentation-indexFunction"><a href="#Must">func Must(f *Foo, err error) *Foo</a></li>
+  <li class="Documentation-indexType"><a href="#Foo">type Foo</a><ul>
+    <li class="Documentation-indexFunction"><a href="#NewFoo">func NewFoo() *Foo</a></li>
+    <li class="Documentation-indexMethod"><a href="#Foo.String">func (f *Foo) String() string</a></li>
+  </ul></li>
+  <li class="Documentation-indexType"><a href="#Bar">type Bar</a></li>
+</ul></div>
+<section class="Documentation-constants"><div class="Documentation-declaration"><pre>const (
+	<span id="A" data-kind="constant">A</span> = 1
+	<span id="B" data-kind="constant">B</span> = 2
+)</pre></div></section>
+<section class="Documentation-variables"><div class="Documentation-declaration"><pre>var <span id="ErrFoo" data-kind="variable">ErrFoo</span> = errors.New("foo")</pre></div></section>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "example.com/foo"})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, pkg.FunctionCount)
+		assert.Equal(t, 2, pkg.TypeCount)
+		assert.Equal(t, 1, pkg.MethodCount)
+		assert.Equal(t, 2, pkg.ConstCount)
+		assert.Equal(t, 1, pkg.VarCount)
+		assert.Equal(t, 9, pkg.DocumentedSymbolCount)
+	})
+}
+
+func TestClient_ImportedByGroups(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<div class="ImportedBy"><ul class="ImportedBy-list">
+  <li class="ImportedBy-details"><details>
+    <summary class="ImportedBy-detailsSummary">github.com/big/mono <span class="ImportedBy-detailsSummaryCount">(2)</span></summary>
+    <div class="ImportedBy-detailsContent">
+      <div><a class="u-breakWord" href="/github.com/big/mono/a">github.com/big/mono/a</a></div>
+      <div><a class="u-breakWord" href="/github.com/big/mono/b">github.com/big/mono/b</a></div>
+    </div>
+  </details></li>
+  <li><a class="u-breakWord" href="/example.com/single">example.com/single</a></li>
+</ul></div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		importedBy, err := client.ImportedBy(ImportedByRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"github.com/big/mono/a", "github.com/big/mono/b", "example.com/single"}, importedBy.ImportedBy)
+		assert.Equal(t, []ImporterGroup{
+			{Module: "github.com/big/mono", Packages: []string{"github.com/big/mono/a", "github.com/big/mono/b"}},
+			{Module: "example.com/single", Packages: []string{"example.com/single"}},
+		}, importedBy.Groups)
+	})
+}
+
+func TestClient_ResolveVanityImport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("go-get") != "1" {
+			rw.WriteHeader(400)
+			return
+		}
+		host := r.Host
+		if strings.HasPrefix(r.URL.Path, "/nometa") {
+			rw.Write([]byte(`<html><head></head></html>`))
+			return
+		}
+		fmt.Fprintf(rw, `<html><head>
+<meta name="go-import" content="%[1]s/other git https://github.com/other/other">
+<meta name="go-import" content="%[1]s/zap git https://github.com/uber-go/zap">
+<meta name="go-source" content="%[1]s/zap https://github.com/uber-go/zap https://github.com/uber-go/zap/tree/master{/dir} https://github.com/uber-go/zap/tree/master{/dir}/{file}#L{line}">
+</head></html>`, host)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	client := New(WithHTTPClient(srv.Client()))
+	info, err := client.ResolveVanityImport(host + "/zap/zapcore")
+	assert.NoError(t, err)
+	assert.Equal(t, &VanityInfo{
+		ImportPrefix:      host + "/zap",
+		VCS:               "git",
+		RepoRoot:          "https://github.com/uber-go/zap",
+		Home:              "https://github.com/uber-go/zap",
+		DirectoryTemplate: "https://github.com/uber-go/zap/tree/master{/dir}",
+		FileTemplate:      "https://github.com/uber-go/zap/tree/master{/dir}/{file}#L{line}",
+	}, info)
+
+	_, err = client.ResolveVanityImport(host + "/nometa")
+	assert.ErrorIs(t, err, ErrNoGoImport)
+
+	_, err = New(WithPkgGoDevOnly()).ResolveVanityImport(host + "/zap")
+	assert.ErrorIs(t, err, ErrExternalFetchDisabled)
+}
+
+func TestNormalizeTime(t *testing.T) {
+	now := time.Date(2024, time.March, 2, 1, 0, 0, 0, time.UTC)
+	cases := []struct {
+		in     string
+		expect string
+	}{
+		{"today", "2024-03-02"},
+		{"yesterday", "2024-03-01"},
+		{"2 days ago", "2024-02-29"},
+		{"3 hours ago", "2024-03-01"},
+		{"Feb 3, 2000", "2000-02-03"},
+	}
+	for _, c := range cases {
+		got, err := normalizeTime(c.in, now)
+		assert.NoError(t, err, c.in)
+		assert.Equal(t, c.expect, got, c.in)
+	}
+
+	// relative dates depend on the time zone of now, absolute ones don't
+	tokyo := time.FixedZone("JST", 9*60*60)
+	got, err := normalizeTime("3 hours ago", now.In(tokyo))
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-03-02", got)
+	got, err = normalizeTime("Feb 3, 2000", now.In(tokyo))
+	assert.NoError(t, err)
+	assert.Equal(t, "2000-02-03", got)
+}
+
+func TestClient_ListLicenses(t *testing.T) {
+	t.Run("scrapes the license filter", func(t *testing.T) {
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			rw.Write([]byte(`<html><body><form action="/search">
+<select name="license">
+  <option value="">Any license</option>
+  <option value="MIT">MIT</option>
+  <option value="Apache-2.0">Apache 2.0</option>
+  <option value="BSD-3-Clause">BSD 3-Clause</option>
+  <option value="MIT">MIT</option>
+</select>
+</form></body></html>`))
+		}, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			licenses, err := client.ListLicenses(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Apache-2.0", "BSD-3-Clause", "MIT"}, licenses)
+		})
+	})
+
+	t.Run("fails without a license filter", func(t *testing.T) {
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			rw.Write([]byte(`<html><body><form action="/search"></form></body></html>`))
+		}, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			_, err := client.ListLicenses(context.Background())
+			assert.ErrorIs(t, err, ErrUnexpectedContent)
+		})
+	})
+}
+
+func TestClient_Authentication(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string]string{}
+	record := func(name string, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received[name] = r.Header.Get("Authorization")
+	}
+
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		record("other", r)
+		rw.Write([]byte(`<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`))
+	}, func(otherAddr string) {
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/same":
+				http.Redirect(rw, r, "/target", http.StatusFound)
+			case "/cross":
+				http.Redirect(rw, r, "http://"+otherAddr+"/target", http.StatusFound)
+			default:
+				record(r.URL.Path, r)
+				rw.Write([]byte(`<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`))
+			}
+		}, func(addr string) {
+			cases := []struct {
+				name   string
+				option func(c *client)
+				expect string
+			}{
+				{"basic", WithBasicAuth("user", "pass"), "Basic dXNlcjpwYXNz"},
+				{"bearer", WithBearerToken("secret"), "Bearer secret"},
+			}
+			for _, c := range cases {
+				t.Run(c.name, func(t *testing.T) {
+					received = map[string]string{}
+					client := New(WithBaseURL("http://"+addr), c.option)
+					for _, pkg := range []string{"direct", "same", "cross"} {
+						_, err := client.DescribePackage(DescribePackageRequest{Package: pkg})
+						assert.NoError(t, err)
+					}
+					assert.Equal(t, c.expect, received["/direct"])
+					assert.Equal(t, c.expect, received["/target"], "same host redirect")
+					assert.Contains(t, received, "other")
+					assert.Empty(t, received["other"], "cross host redirect")
+				})
+			}
+		})
+	})
+}
+
+func TestParseCoverageBadge(t *testing.T) {
+	cases := []struct {
+		alt, src   string
+		expect     float64
+		expectData bool
+	}{
+		{"coverage", "https://img.shields.io/badge/coverage-85.3%25-green", 85.3, true},
+		{"Coverage: 0%", "https://example.com/badge.svg", 0, true},
+		{"codecov", "https://codecov.io/gh/foo/bar/branch/main/graph/badge.svg", 0, false},
+		{"build", "https://img.shields.io/badge/build-100%25-green", 0, false},
+	}
+	for _, c := range cases {
+		percent, ok := parseCoverageBadge(c.alt, c.src)
+		assert.Equal(t, c.expectData, ok, c.src)
+		assert.Equal(t, c.expect, percent, c.src)
+	}
+}
+
+func TestClient_DescribePackageCoverage(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		page := `<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`
+		if r.URL.Path == "/covered" {
+			page += `<div class="UnitReadme-content"><img alt="coverage" src="https://img.shields.io/badge/coverage-0%25-red"/></div>`
+		}
+		rw.Write([]byte(page))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "covered"})
+		assert.NoError(t, err)
+		assert.True(t, pkg.HasCoverageData())
+		assert.Zero(t, pkg.CoveragePercent)
+
+		pkg, err = client.DescribePackage(DescribePackageRequest{Package: "uncovered"})
+		assert.NoError(t, err)
+		assert.False(t, pkg.HasCoverageData())
+	})
+}
+
+func TestGitHostType_Text(t *testing.T) {
+	for hostType := GitHostUnknown; hostType <= GitHostGitea; hostType++ {
+		text, err := hostType.MarshalText()
+		assert.NoError(t, err)
+		var parsed GitHostType
+		assert.NoError(t, parsed.UnmarshalText(text))
+		assert.Equal(t, hostType, parsed)
+	}
+	assert.Equal(t, "github", GitHostGitHub.String())
+
+	var parsed GitHostType
+	assert.NoError(t, parsed.UnmarshalText([]byte("GitLab")))
+	assert.Equal(t, GitHostGitLab, parsed)
+	assert.Error(t, parsed.UnmarshalText([]byte("bitbucket")))
+	_, err := GitHostType(42).MarshalText()
+	assert.Error(t, err)
+}
+
+func TestNormalizeRepoURL(t *testing.T) {
+	cases := []struct {
+		in        string
+		expect    string
+		expectErr bool
+	}{
+		{in: "github.com/foo/bar", expect: "https://github.com/foo/bar"},
+		{in: "git@github.com:foo/bar.git", expect: "https://github.com/foo/bar"},
+		{in: "ssh://git@github.com/foo/bar.git", expect: "https://github.com/foo/bar"},
+		{in: "https://gitlab.com/foo/bar.git", expect: "https://gitlab.com/foo/bar"},
+		{in: "", expectErr: true},
+		{in: "ssh://", expectErr: true},
+		{in: "not a url", expectErr: true},
+		{in: "git@github.com:foo/bar", expectErr: true},
+	}
+	for _, c := range cases {
+		got, err := NormalizeRepoURL(c.in)
+		if c.expectErr {
+			assert.ErrorIs(t, err, ErrInvalidRepoURL, c.in)
+			continue
+		}
+		assert.NoError(t, err, c.in)
+		assert.Equal(t, c.expect, got)
+		assert.NotEqual(t, GitHostUnknown, IdentifyGitHost(got))
+	}
+}
+
+func TestNormalizeRepoURL_Forms(t *testing.T) {
+	cases := map[string]string{
+		"github.com/foo/bar":                     "https://github.com/foo/bar",
+		"https://github.com/foo/bar":             "https://github.com/foo/bar",
+		"http://git.example.com/foo/bar":         "http://git.example.com/foo/bar",
+		"https://gitlab.com/foo/bar.git":         "https://gitlab.com/foo/bar",
+		"git@github.com:foo/bar.git":             "https://github.com/foo/bar",
+		"ssh://git@github.com/foo/bar.git":       "https://github.com/foo/bar",
+		"ssh://git@github.com/foo/bar":           "https://github.com/foo/bar",
+		"ssh://git@gitlab.com:2222/foo/bar.git":  "https://gitlab.com/foo/bar",
+		"ssh://github.com/foo/bar.git":           "https://github.com/foo/bar",
+		"git+ssh://git@codeberg.org/foo/bar.git": "https://codeberg.org/foo/bar",
+		"gopkg.in/yaml.v3":                       "https://github.com/go-yaml/yaml",
+	}
+	for in, expect := range cases {
+		assert.Equal(t, expect, normalizeRepoURL(in), in)
+	}
+}
+
+func TestClient_KnownHosts(t *testing.T) {
+	client := New(WithGiteaHost("git.example.com"))
+	hosts := client.KnownHosts()
+	assert.Equal(t, GitHostGitHub, hosts["github.com"])
+	assert.Equal(t, GitHostGitea, hosts["git.example.com"])
+	assert.Equal(t, GitHostGitea, client.identifyGitHost("https://git.example.com/foo/bar"))
+	assert.Equal(t, GitHostUnknown, IdentifyGitHost("https://git.example.com/foo/bar"))
+	assert.NotContains(t, New().KnownHosts(), "git.example.com")
+}
+
+func TestClient_ExtractIssueCount(t *testing.T) {
+	cases := []struct {
+		name    string
+		html    string
+		extract func(c *client, url string) (repoInfo, error)
+		expect  int
+	}{
+		{
+			name: "github exact count from title",
+			html: `<div><span id="issues-repo-tab-count" title="1,234">1.2k</span></div>`,
+			extract: func(c *client, url string) (repoInfo, error) {
+				return c.extractGitHubInfo(url, true)
+			},
+			expect: 1234,
+		},
+		{
+			name: "github abbreviated count",
+			html: `<div><span id="issues-repo-tab-count">2.5k</span></div>`,
+			extract: func(c *client, url string) (repoInfo, error) {
+				return c.extractGitHubInfo(url, true)
+			},
+			expect: 2500,
+		},
+		{
+			name:    "gitlab",
+			html:    `<div><span class="issues_count">17</span></div>`,
+			extract: (*client).extractGitLabInfo,
+			expect:  17,
+		},
+		{
+			name: "missing element",
+			html: `<div></div>`,
+			extract: func(c *client, url string) (repoInfo, error) {
+				return c.extractGitHubInfo(url, true)
+			},
+			expect: 0,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				rw.Write([]byte(c.html))
+			}, func(addr string) {
+				info, err := c.extract(New(), "http://"+addr)
+				assert.NoError(t, err)
+				assert.Equal(t, c.expect, info.issueCount)
+			})
+		})
+	}
+}
+
+func TestClient_ExtractContributorCount(t *testing.T) {
+	cases := []struct {
+		name    string
+		html    string
+		extract func(c *client, url string) (repoInfo, error)
+		expect  int
+	}{
+		{
+			name: "github exact count from title",
+			html: `<div><a id="contributor-link" href="/foo/bar/graphs/contributors">Contributors <span class="Counter" title="1,024">1k</span></a></div>`,
+			extract: func(c *client, url string) (repoInfo, error) {
+				return c.extractGitHubInfo(url, true)
+			},
+			expect: 1024,
+		},
+		{
+			name: "github count from text",
+			html: `<div><a id="contributor-link" href="/foo/bar/graphs/contributors">Contributors 42</a></div>`,
+			extract: func(c *client, url string) (repoInfo, error) {
+				return c.extractGitHubInfo(url, true)
+			},
+			expect: 42,
+		},
+		{
+			name:    "gitlab",
+			html:    `<div><span class="contributors-count">8</span></div>`,
+			extract: (*client).extractGitLabInfo,
+			expect:  8,
+		},
+		{
+			name: "missing element",
+			html: `<div></div>`,
+			extract: func(c *client, url string) (repoInfo, error) {
+				return c.extractGitHubInfo(url, true)
+			},
+			expect: 0,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				rw.Write([]byte(c.html))
+			}, func(addr string) {
+				info, err := c.extract(New(), "http://"+addr)
+				assert.NoError(t, err)
+				assert.Equal(t, c.expect, info.contributorCount)
+			})
+		})
+	}
+}
+
+func TestClient_WithCache(t *testing.T) {
+	var mu sync.Mutex
+	var requests, notModified int
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		rw.Header().Set("ETag", `"v1"`)
+		rw.Write([]byte(`<div data-test-id="UnitHeader-version"><a>Version: v1.0.0</a></div>
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`))
+	}, func(addr string) {
+		now := time.Now()
+		client := New(WithBaseURL("http://"+addr), WithCache(time.Minute))
+		client.cache.now = func() time.Time { return now }
+		describe := func() {
+			pkg, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+			assert.NoError(t, err)
+			assert.Equal(t, "v1.0.0", pkg.Version)
+		}
+
+		describe()
+		describe()
+		assert.Equal(t, 1, requests, "fresh entries are served from the cache")
+
+		now = now.Add(2 * time.Minute)
+		describe()
+		assert.Equal(t, 2, requests)
+		assert.Equal(t, 1, notModified, "stale entries are revalidated")
+		describe()
+		assert.Equal(t, 2, requests, "revalidation refreshes the TTL")
+
+		metrics := client.Metrics()
+		assert.Equal(t, uint64(2), metrics.CacheHits)
+		assert.Equal(t, uint64(1), metrics.CacheMisses)
+		assert.Equal(t, uint64(1), metrics.CacheRevalidations)
+	})
+}
+
+func TestClient_NegativeCache(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	published := false
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if !published {
+			http.NotFound(rw, r)
+			return
+		}
+		rw.Write([]byte(`<div data-test-id="UnitHeader-version"><a>Version: v1.0.0</a></div>
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`))
+	}, func(addr string) {
+		now := time.Now()
+		client := New(WithBaseURL("http://"+addr), WithCache(time.Hour), WithNegativeCacheTTL(time.Minute))
+		client.cache.now = func() time.Time { return now }
+		publish := func(v bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			published = v
+		}
+		describe := func(fresh bool) (*Package, error) {
+			return client.DescribePackage(DescribePackageRequest{Package: "somepackage", Fresh: fresh})
+		}
+
+		_, err := describe(false)
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = describe(false)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, 1, requests, "not found answers are cached")
+
+		_, err = describe(true)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, 2, requests, "Fresh skips negative entries")
+
+		publish(true)
+		_, err = describe(false)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, 2, requests)
+
+		now = now.Add(2 * time.Minute)
+		pkg, err := describe(false)
+		assert.NoError(t, err)
+		assert.Equal(t, "v1.0.0", pkg.Version)
+		assert.Equal(t, 3, requests, "negative entries expire before positive ones")
+		_, err = describe(false)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, requests, "the page replaced the negative entry")
+
+		// Fresh also evicts a negative entry that hasn't expired yet
+		publish(false)
+		_, err = client.Versions(VersionsRequest{Package: "otherpackage"})
+		assert.ErrorIs(t, err, ErrNotFound)
+		publish(true)
+		_, err = client.Versions(VersionsRequest{Package: "otherpackage", Fresh: true})
+		assert.NoError(t, err)
+		_, err = client.Versions(VersionsRequest{Package: "otherpackage"})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, requests)
+	})
+
+	requests = 0
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		http.NotFound(rw, r)
+	}, func(addr string) {
+		client := New(WithBaseURL("http://"+addr), WithCache(time.Hour), WithNegativeCacheTTL(0))
+		for i := 0; i < 2; i++ {
+			_, err := client.ImportedBy(ImportedByRequest{Package: "somepackage"})
+			assert.ErrorIs(t, err, ErrNotFound)
+		}
+		assert.Equal(t, 2, requests, "a zero TTL disables negative caching")
+	})
+}
+
+func TestClient_VersionsVulnerabilities(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<html><body><div class="Versions-list">
+  <div class="Version-major">v1</div>
+  <div class="Version-tag"><a class="js-versionLink" href="/somepackage@v1.1.0">v1.1.0</a></div>
+  <div class="Version-commitTime">Feb 3, 2021</div>
+  <div class="Version-tag"><a class="js-versionLink" href="/somepackage@v1.0.0">v1.0.0</a>
+    <a class="go-Chip" href="/vuln/GO-2021-0001">GO-2021-0001</a></div>
+  <div class="Version-details">
+    <details><summary class="Version-summary"><span class="Version-dot"></span> Jan 2, 2020
+      <span><a href="/vuln/GO-2021-0002">GO-2021-0002</a></span></summary></details>
+    <a href="/vuln/GO-2021-0001">GO-2021-0001</a>
+  </div>
+</div></body></html>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		versions, err := client.Versions(VersionsRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.Len(t, versions.Versions, 2)
+		assert.Empty(t, versions.Versions[0].Vulnerabilities)
+		assert.Equal(t, []string{"GO-2021-0001", "GO-2021-0002"}, versions.Versions[1].Vulnerabilities)
+		assert.Equal(t, "2020-01-02", versions.Versions[1].Date)
+	})
+}
+
+func TestClient_DescribePackageMajorVersions(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		page := `<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`
+		if strings.HasPrefix(r.URL.Path, "/github.com/foo/multi") {
+			page += `<div class="UnitHeader-majorVersions">
+  <a href="/github.com/foo/multi">v1</a>
+  <a href="/github.com/foo/multi/v2@v2.3.0">v2</a>
+  <a href="/github.com/foo/multi/v3?tab=doc">v3</a>
+</div>`
+		}
+		rw.Write([]byte(page))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "github.com/foo/multi/sub"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"github.com/foo/multi", "github.com/foo/multi/v2", "github.com/foo/multi/v3"}, pkg.MajorVersions)
+
+		pkg, err = client.DescribePackage(DescribePackageRequest{Package: "github.com/foo/single/v2/sub"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"github.com/foo/single/v2"}, pkg.MajorVersions)
+	})
+}
+
+func TestClient_Autocomplete(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "100", r.URL.Query().Get("limit"))
+		rw.Write([]byte(searchSnippetsHTML))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		suggestions, err := client.Autocomplete(context.Background(), "github.com/Foo", 5)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"github.com/foo/bar/baz"}, suggestions)
+
+		suggestions, err = client.Autocomplete(context.Background(), "golang.org/x", 5)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{}, suggestions)
+
+		_, err = client.Autocomplete(context.Background(), "github.com", 0)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	// canceling aborts the search in flight
+	arrived := make(chan struct{}, 1)
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}, func(addr string) {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-arrived
+			cancel()
+		}()
+		start := time.Now()
+		_, err := New(WithBaseURL("http://"+addr)).Autocomplete(ctx, "github.com", 5)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+}
+
+func TestClient_Exists(t *testing.T) {
+	cases := []struct {
+		name        string
+		handler     http.HandlerFunc
+		expect      bool
+		expectErrIs error
+	}{
+		{
+			name: "head finds the page",
+			handler: func(rw http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodHead, r.Method)
+				rw.WriteHeader(200)
+			},
+			expect: true,
+		},
+		{
+			name: "head finds nothing",
+			handler: func(rw http.ResponseWriter, r *http.Request) {
+				rw.WriteHeader(404)
+			},
+		},
+		{
+			name: "falls back to the page",
+			handler: func(rw http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					rw.WriteHeader(405)
+					return
+				}
+				assert.Equal(t, "/example.com/foo", r.URL.Path)
+				rw.Write([]byte(`<html><body></body></html>`))
+			},
+			expect: true,
+		},
+		{
+			name: "falls back to the page of an unknown path",
+			handler: func(rw http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					rw.WriteHeader(405)
+					return
+				}
+				// the badge is served for any path
+				if strings.HasPrefix(r.URL.Path, "/badge/") {
+					rw.Header().Set("Content-Type", "image/svg+xml")
+					rw.Write([]byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`))
+					return
+				}
+				http.NotFound(rw, r)
+			},
+		},
+		{
+			name: "unexpected status",
+			handler: func(rw http.ResponseWriter, r *http.Request) {
+				rw.WriteHeader(500)
+			},
+			expectErrIs: ErrUnexpectedStatus,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withHTTPServer("/", c.handler, func(addr string) {
+				client := New(WithBaseURL("http://" + addr))
+				exists, err := client.Exists("example.com/foo")
+				if c.expectErrIs != nil {
+					assert.ErrorIs(t, err, c.expectErrIs)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, c.expect, exists)
+			})
+		})
+	}
+
+	t.Run("network errors are distinguishable", func(t *testing.T) {
+		client := New(WithBaseURL("http://127.0.0.1:1"))
+		_, err := client.Exists("example.com/foo")
+		assert.ErrorIs(t, err, ErrUnreachable)
+	})
+}
+
+func TestInferModulePath(t *testing.T) {
+	cases := map[string]string{
+		"github.com/foo/bar/baz":         "github.com/foo/bar",
+		"github.com/foo/bar":             "github.com/foo/bar",
+		"github.com/foo/bar/v2/baz":      "github.com/foo/bar/v2",
+		"example.com/mod/v3":             "example.com/mod/v3",
+		"gopkg.in/yaml.v3":               "gopkg.in/yaml.v3",
+		"gopkg.in/user/pkg.v2/sub":       "gopkg.in/user/pkg.v2",
+		"golang.org/x/tools/go/packages": "golang.org/x/tools/go/packages",
+	}
+	for in, expect := range cases {
+		assert.Equal(t, expect, inferModulePath(in), in)
+	}
+}
+
+func TestClient_SearchModulePath(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			rw.Write([]byte(`<div class="SearchResults"></div>`))
+			return
+		}
+		rw.Write([]byte(`<div class="SearchResults">
+<div class="SearchSnippet">
+  <div class="SearchSnippet-headerContainer"><h2><a href="/example.com/mod/sub/pkg">example.com/mod/sub/pkg</a></h2></div>
+  <div class="SearchSnippet-sub">Other packages in module <a data-test-id="snippet-module" href="/example.com/mod/sub">example.com/mod/sub</a></div>
+</div>
+<div class="SearchSnippet">
+  <div class="SearchSnippet-headerContainer"><h2><a href="/github.com/foo/bar/baz">github.com/foo/bar/baz</a></h2></div>
+</div>
+</div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		results, err := client.Search(SearchRequest{Query: "foo", Limit: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, "example.com/mod/sub", results.Results[0].ModulePath)
+		assert.Equal(t, "github.com/foo/bar", results.Results[1].ModulePath)
+	})
+}
+
+func TestClient_MixedCasePaths(t *testing.T) {
+	var requested []string
+	var queries []string
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		switch {
+		case r.URL.Path == "/search":
+			queries = append(queries, r.URL.Query().Get("q"))
+			rw.Write([]byte(`<div class="SearchResults"></div>`))
+		case r.URL.Path == "/proxy/github.com/!sirupsen/logrus/@v/master.info":
+			rw.Write([]byte(`{"Version":"v1.0.1-0.20170101000000-abcdefabcdef"}`))
+		case strings.HasPrefix(r.URL.Path, "/github.com/Sirupsen/logrus"):
+			rw.Write([]byte(`<div data-test-id="UnitHeader-version"><a>Version: v1.0.0</a></div>
+<div class="UnitHeader-titleHeading">logrus</div><div>package</div>
+<div class="Versions-list"></div>`))
+		default:
+			rw.WriteHeader(404)
+		}
+	}, func(addr string) {
+		client := New(WithBaseURL("http://"+addr), WithProxyURL("http://"+addr+"/proxy"))
+
+		_, err := client.DescribePackage(DescribePackageRequest{Package: "github.com/Sirupsen/logrus"})
+		assert.NoError(t, err)
+		_, err = client.Versions(VersionsRequest{Package: "github.com/Sirupsen/logrus"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"/github.com/Sirupsen/logrus",
+			"/github.com/Sirupsen/logrus?tab=versions",
+		}, requested)
+
+		// the proxy wants the path case-encoded, pkg.go.dev doesn't
+		requested = nil
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "github.com/Sirupsen/logrus", Version: "master"})
+		assert.NoError(t, err)
+		assert.Equal(t, "v1.0.1-0.20170101000000-abcdefabcdef", pkg.Version)
+		assert.Equal(t, []string{
+			"/proxy/github.com/!sirupsen/logrus/@v/master.info",
+			"/github.com/Sirupsen/logrus@v1.0.1-0.20170101000000-abcdefabcdef",
+		}, requested)
+
+		_, err = client.Search(SearchRequest{Query: "Ünïcode & co #1"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Ünïcode & co #1"}, queries)
+	})
+}
+
+func TestClient_SubscribeToVulnerabilities(t *testing.T) {
+	var mu sync.Mutex
+	polls := map[string]int{}
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		mod := r.URL.Query().Get("q")
+		mu.Lock()
+		polls[mod]++
+		poll := polls[mod]
+		mu.Unlock()
+
+		entries := `<div class="VulnList-entry"><a href="/vuln/GO-2022-0001">GO-2022-0001</a>
+<p class="VulnList-summary">Shared report</p><span class="VulnList-alias">CVE-2022-1111</span></div>`
+		if mod == "example.com/b" && poll > 1 {
+			entries += `<div class="VulnList-entry"><a href="/vuln/GO-2023-0002">GO-2023-0002</a>
+<p class="VulnList-summary">New report</p></div>`
+		}
+		rw.Write([]byte(`<div class="VulnList">` + entries + `</div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://"+addr), WithPollInterval(10*time.Millisecond))
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan Vulnerability)
+		done := make(chan error)
+		go func() {
+			done <- client.SubscribeToVulnerabilities(ctx, []string{"example.com/a", "example.com/b"}, ch)
+		}()
+
+		first := <-ch
+		assert.Equal(t, Vulnerability{
+			ID:      "GO-2022-0001",
+			Module:  "example.com/a",
+			Summary: "Shared report",
+			Aliases: []string{"CVE-2022-1111"},
+			URL:     "http://" + addr + "/vuln/GO-2022-0001",
+		}, first)
+		second := <-ch
+		assert.Equal(t, "GO-2023-0002", second.ID)
+		assert.Equal(t, "example.com/b", second.Module)
+
+		// known reports aren't sent again
+		select {
+		case vuln := <-ch:
+			t.Errorf("unexpected report %s", vuln.ID)
+		case <-time.After(50 * time.Millisecond):
+		}
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+
+		err := client.SubscribeToVulnerabilities(context.Background(), nil, ch)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+}
+
+func TestClient_DescribePackagePlatform(t *testing.T) {
+	var queries []string
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		platform := "linux/amd64"
+		if goos := r.URL.Query().Get("GOOS"); goos != "" {
+			platform = goos + "/" + r.URL.Query().Get("GOARCH")
+		}
+		options := ""
+		for _, option := range []string{"linux/amd64", "windows/amd64"} {
+			selected := ""
+			if option == platform {
+				selected = " selected"
+			}
+			options += fmt.Sprintf(`<option value="%s"%s>%s</option>`, option, selected, option)
+		}
+		rw.Write([]byte(`<div class="UnitHeader-titleHeading">Heading</div><div>package</div>
+<div class="Documentation-buildContext"><select>` + options + `</select></div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://"+addr), WithCache(time.Minute))
+		describe := func(platform string) string {
+			pkg, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage", Platform: platform})
+			assert.NoError(t, err)
+			return pkg.EffectivePlatform
+		}
+
+		assert.Equal(t, "linux/amd64", describe(""), "pkg.go.dev's own pick is reported")
+		assert.Equal(t, "windows/amd64", describe("windows/amd64"))
+		// variants are cached apart
+		assert.Equal(t, "linux/amd64", describe(""))
+		assert.Equal(t, "windows/amd64", describe("windows/amd64"))
+		assert.Equal(t, []string{"", "GOARCH=amd64&GOOS=windows"}, queries)
+	})
+}
+
+func TestClient_DocIndex(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/example.com/list" {
+			rw.WriteHeader(404)
+			return
+		}
+		rw.Write([]byte(`<div class="Documentation-index"><ul class="Documentation-indexList">
+<li class="Documentation-indexConstants"><a href="#pkg-constants">Constants</a></li>
+<li class="Documentation-indexFunction"><a href="#Sum">func Sum[T Number](values ...T) T</a></li>
+<li class="Documentation-indexType"><a href="#List">type List</a>
+  <ul>
+    <li><a href="#New">func New[T any]() *List[T]</a></li>
+    <li class="Documentation-indexMethod"><a href="#List.Push">func (l *List[T]) Push(v T)</a></li>
+  </ul>
+</li>
+</ul>
+<ul class="Documentation-examplesList"><li><a href="#example-List">List</a></li></ul></div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		index, err := client.DocIndex(context.Background(), "example.com/list")
+		assert.NoError(t, err)
+		assert.Equal(t, &DocIndex{Package: "example.com/list", Sections: []DocIndexNode{
+			{Kind: "section", Title: "Constants", Anchor: "pkg-constants"},
+			{Kind: "section", Title: "Functions", Anchor: "pkg-functions", Children: []DocIndexNode{
+				{Kind: "function", Title: "func Sum[T Number](values ...T) T", Anchor: "Sum"},
+			}},
+			{Kind: "section", Title: "Types", Anchor: "pkg-types", Children: []DocIndexNode{
+				{Kind: "type", Title: "type List", Anchor: "List", Children: []DocIndexNode{
+					{Kind: "function", Title: "func New[T any]() *List[T]", Anchor: "New"},
+					{Kind: "method", Title: "func (l *List[T]) Push(v T)", Anchor: "List.Push"},
+				}},
+			}},
+			{Kind: "section", Title: "Examples", Anchor: "pkg-examples", Children: []DocIndexNode{
+				{Kind: "example", Title: "List", Anchor: "example-List"},
+			}},
+		}}, index)
+
+		_, err = client.DocIndex(context.Background(), "example.com/missing")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestClient_LatestVersionByModule(t *testing.T) {
+	var requested []string
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch r.URL.Path {
+		case "/proxy/github.com/foo/bar/@latest", "/proxy/example.com/unstable/@latest":
+			rw.Write([]byte(`{"Version":"v1.0.0"}`))
+		case "/github.com/foo/bar":
+			rw.Write([]byte(versionsPlainHTML))
+		case "/example.com/unstable":
+			rw.Write([]byte(`<html><body><div class="Versions-list">
+  <div class="Version-major">v0</div>
+  <div class="Version-tag"><a class="js-versionLink" href="/example.com/unstable@v0.1.0">v0.1.0</a></div>
+  <div class="Version-commitTime">Jan 2, 2020</div>
+</div></body></html>`))
+		default:
+			rw.WriteHeader(404)
+		}
+	}, func(addr string) {
+		client := New(WithBaseURL("http://"+addr), WithProxyURL("http://"+addr+"/proxy"))
+
+		root, err := client.ResolveModuleForPackage(context.Background(), "github.com/foo/bar/baz/qux")
+		assert.NoError(t, err)
+		assert.Equal(t, "github.com/foo/bar", root)
+
+		requested = nil
+		latest, err := client.LatestVersionByModule(context.Background(), "github.com/foo/bar/baz")
+		assert.NoError(t, err)
+		assert.Equal(t, "v2.1.0", latest.FullVersion)
+		assert.Equal(t, []string{
+			"/proxy/github.com/foo/bar/baz/@latest",
+			"/proxy/github.com/foo/bar/@latest",
+			"/github.com/foo/bar",
+		}, requested)
+
+		_, err = client.LatestVersionByModule(context.Background(), "example.com/unstable")
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = client.ResolveModuleForPackage(context.Background(), "nowhere.example/pkg")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+// genericDocHTML mimics the documentation of golang.org/x/exp/slices and of
+// a generic container type, with constraints linked as pkg.go.dev does
+const genericDocHTML = `
+<html><body><div class="Documentation-index"><ul class="Documentation-indexList">
+<li class="Documentation-indexFunction"><a href="#IndexFunc">func IndexFunc[S ~[]E, E any](s S, f func(E) bool) int</a></li>
+<li class="Documentation-indexFunction"><a href="#Max">func Max[S ~[]E, E cmp.Ordered](x S) E</a></li>
+<li class="Documentation-indexType"><a href="#Tree">type Tree</a>
+  <ul>
+    <li><a href="#NewTree">func NewTree[K constraints.Ordered, V any](less func(a, b K) bool) *Tree[K, V]</a></li>
+    <li class="Documentation-indexMethod"><a href="#Tree.Get">func (t *Tree[K, V]) Get(key K) (V, bool)</a></li>
+  </ul>
+</li>
+</ul></div>
+<div class="Documentation-content">
+<div class="Documentation-function">
+  <h4 tabindex="-1" id="IndexFunc" data-kind="function" class="Documentation-functionHeader">func <a href="#IndexFunc">IndexFunc</a></h4>
+  <div class="Documentation-declaration"><pre>func IndexFunc[S ~[]E, E <a href="/builtin#any">any</a>](s S, f func(E) <a href="/builtin#bool">bool</a>) <a href="/builtin#int">int</a></pre></div>
+  <p>IndexFunc returns the first index i satisfying f(s[i]).</p>
+</div>
+<div class="Documentation-function">
+  <h4 tabindex="-1" id="Max" data-kind="function" class="Documentation-functionHeader">func <a href="#Max">Max</a></h4>
+  <div class="Documentation-declaration"><pre>func Max[S ~[]E, E <a href="/cmp">cmp</a>.<a href="/cmp#Ordered">Ordered</a>](x S) E</pre></div>
+  <p>Max returns the maximal value in x.</p>
+</div>
+<div class="Documentation-type">
+  <h4 tabindex="-1" id="Tree" data-kind="type" class="Documentation-typeHeader">type <a href="#Tree">Tree</a></h4>
+  <div class="Documentation-declaration"><pre>type Tree[K <a href="/golang.org/x/exp/constraints">constraints</a>.<a href="/golang.org/x/exp/constraints#Ordered">Ordered</a>, V <a href="/builtin#any">any</a>] struct {
+	// contains filtered or unexported fields
+}</pre></div>
+  <p>Tree is a sorted map.</p>
+  <div class="Documentation-typeMethod">
+    <h4 tabindex="-1" id="Tree.Get" data-kind="method" class="Documentation-typeMethodHeader">func (*Tree[K, V]) <a href="#Tree.Get">Get</a></h4>
+    <div class="Documentation-declaration"><pre>func (t *<a href="#Tree">Tree</a>[K, V]) Get(key K) (V, <a href="/builtin#bool">bool</a>)</pre></div>
+    <p>Get looks up key.</p>
+  </div>
+</div>
+</div></body></html>`
+
+func TestClient_GenericSignatures(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(genericDocHTML))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		declarations := map[string]string{
+			"IndexFunc": "func IndexFunc[S ~[]E, E any](s S, f func(E) bool) int",
+			"Max":       "func Max[S ~[]E, E cmp.Ordered](x S) E",
+			"Tree":      "type Tree[K constraints.Ordered, V any] struct {\n\t// contains filtered or unexported fields\n}",
+			"Tree.Get":  "func (t *Tree[K, V]) Get(key K) (V, bool)",
+		}
+		for name, declaration := range declarations {
+			sym, err := client.Symbol(context.Background(), "golang.org/x/exp/slices", name)
+			assert.NoError(t, err)
+			assert.Equal(t, declaration, sym.Declaration, name)
+		}
+
+		index, err := client.DocIndex(context.Background(), "golang.org/x/exp/slices")
+		assert.NoError(t, err)
+		assert.Equal(t, "func Max[S ~[]E, E cmp.Ordered](x S) E", index.Sections[0].Children[1].Title)
+		tree := index.Sections[1].Children[0]
+		assert.Equal(t, []DocIndexNode{
+			{Kind: "function", Title: "func NewTree[K constraints.Ordered, V any](less func(a, b K) bool) *Tree[K, V]", Anchor: "NewTree"},
+			{Kind: "method", Title: "func (t *Tree[K, V]) Get(key K) (V, bool)", Anchor: "Tree.Get"},
+		}, tree.Children)
+	})
+}
+
+func TestClient_Archived(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github/archived":
+			rw.Write([]byte(`<div><div class="archived-notice-badge">This repository was archived by the owner.</div></div>`))
+		case "/example.com/archived":
+			rw.Write([]byte(`<div class="UnitHeader-titleHeading">archived</div><div>package</div>
+<div class="go-Message go-Message--warning">The repository of this module has been archived.</div>`))
+		default:
+			rw.Write([]byte(`<div class="UnitHeader-titleHeading">active</div><div>package</div>`))
+		}
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+
+		info, err := client.extractGitHubInfo("http://"+addr+"/github/archived", true)
+		assert.NoError(t, err)
+		assert.True(t, info.archived)
+		info, err = client.extractGitHubInfo("http://"+addr+"/github/active", true)
+		assert.NoError(t, err)
+		assert.False(t, info.archived)
+
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "example.com/archived"})
+		assert.NoError(t, err)
+		assert.True(t, pkg.IsArchived)
+		pkg, err = client.DescribePackage(DescribePackageRequest{Package: "example.com/active"})
+		assert.NoError(t, err)
+		assert.False(t, pkg.IsArchived)
+	})
+}
+
+const valuesDocHTML = `
+<html><body><div class="Documentation-content">
+<section class="Documentation-constants">
+  <div class="Documentation-declaration"><pre>const <span id="DefaultTimeout" data-kind="constant">DefaultTimeout</span> = 30 * <a href="/time">time</a>.<a href="/time#Second">Second</a></pre></div>
+  <p>DefaultTimeout is used unless configured.</p>
+  <div class="Documentation-declaration"><pre>const (
+	<span id="LevelDebug" data-kind="constant">LevelDebug</span> = <a href="/builtin#iota">iota</a>
+	<span id="LevelInfo" data-kind="constant">LevelInfo</span>
+)</pre></div>
+</section>
+<section class="Documentation-variables">
+  <div class="Documentation-declaration"><pre>var <span id="ErrClosed" data-kind="variable">ErrClosed</span> = <a href="/errors">errors</a>.<a href="/errors#New">New</a>("closed")</pre></div>
+  <p>ErrClosed is returned after Close.</p>
+</section>
+</div></body></html>`
+
+func TestClient_Values(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(valuesDocHTML))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		blocks, err := client.Values(context.Background(), "somepackage")
+		assert.NoError(t, err)
+		assert.Equal(t, []ValueBlock{
+			{
+				Kind:        "constant",
+				Names:       []string{"DefaultTimeout"},
+				Declaration: "const DefaultTimeout = 30 * time.Second",
+				Synopsis:    "DefaultTimeout is used unless configured.",
+			},
+			{
+				Kind:        "constant",
+				Names:       []string{"LevelDebug", "LevelInfo"},
+				Declaration: "const (\n\tLevelDebug = iota\n\tLevelInfo\n)",
+			},
+			{
+				Kind:        "variable",
+				Names:       []string{"ErrClosed"},
+				Declaration: `var ErrClosed = errors.New("closed")`,
+				Synopsis:    "ErrClosed is returned after Close.",
+			},
+		}, blocks)
+
+		// Symbol returns the whole block of a constant
+		sym, err := client.Symbol(context.Background(), "somepackage", "LevelInfo")
+		assert.NoError(t, err)
+		assert.Equal(t, "constant", sym.Kind)
+		assert.Equal(t, "const (\n\tLevelDebug = iota\n\tLevelInfo\n)", sym.Declaration)
+
+		// renamed declarations are found through the selectors
+		renamed := strings.ReplaceAll(valuesDocHTML, "Documentation-declaration", "Decl")
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			rw.Write([]byte(renamed))
+		}, func(renamedAddr string) {
+			selectors := DefaultSelectors()
+			selectors.DocConstantBlock = ".Documentation-constants .Decl"
+			selectors.DocVariableBlock = ".Documentation-variables .Decl"
+			selectors.DocDeclaration = ".Decl"
+			client := New(WithBaseURL("http://"+renamedAddr), WithSelectors(selectors))
+			renamedBlocks, err := client.Values(context.Background(), "somepackage")
+			assert.NoError(t, err)
+			assert.Equal(t, blocks, renamedBlocks)
+
+			sym, err := client.Symbol(context.Background(), "somepackage", "LevelInfo")
+			assert.NoError(t, err)
+			assert.Equal(t, "const (\n\tLevelDebug = iota\n\tLevelInfo\n)", sym.Declaration)
+		})
+	})
+}
+
+func TestClient_VersionsChanges(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<html><body><div class="Versions-list">
+  <div class="Version-major">v1</div>
+  <div class="Version-tag"><a class="js-versionLink" href="/somepackage@v1.1.0">v1.1.0</a></div>
+  <div class="Version-details">
+    <details><summary class="Version-summary"><span class="Version-dot"></span> Mar 4, 2021 </summary>
+      <div class="Versions-symbols">
+        <div class="Versions-symbol"><a href="/somepackage@v1.1.0#Foo">func Foo</a><div class="Versions-symbolSynopsis">func Foo() error</div></div>
+        <ul class="Versions-symbolImpacted"><li><a href="/example.com/a">example.com/a</a></li><li><a href="/example.com/b">example.com/b</a></li></ul>
+        <div class="Versions-symbol"><a href="/somepackage@v1.1.0#Client.Close">method Close</a></div>
+      </div>
+    </details>
+  </div>
+</div></body></html>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		versions, err := client.Versions(VersionsRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.Len(t, versions.Versions, 1)
+		assert.Equal(t, []Change{
+			{
+				URL:              "http://" + addr + "/somepackage@v1.1.0#Foo",
+				Symbol:           "Foo",
+				SymbolSynopsis:   "func Foo() error",
+				ImpactedPackages: []string{"example.com/a", "example.com/b"},
+			},
+			{
+				URL:    "http://" + addr + "/somepackage@v1.1.0#Client.Close",
+				Symbol: "Client.Close",
+			},
+		}, versions.Versions[0].Changes)
+	})
+}
+
+func TestClient_Truncation(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/complete" {
+			rw.Write([]byte(`<div class="UnitHeader-titleHeading">complete</div><div>package</div>` + valuesDocHTML))
+			return
+		}
+		rw.Write([]byte(`<div class="UnitHeader-titleHeading">huge</div><div>package</div>
+<div class="UnitReadme"><div class="UnitReadme-content"><p>Readme</p></div><button class="js-readmeExpand">Expand</button></div>
+<div class="Documentation">` + valuesDocHTML + `<div class="Documentation-truncated">Documentation truncated</div></div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "huge"})
+		assert.NoError(t, err)
+		assert.True(t, pkg.Truncated)
+		assert.Equal(t, []string{"documentation", "readme"}, pkg.TruncatedSections)
+		index, err := client.DocIndex(context.Background(), "huge")
+		assert.NoError(t, err)
+		assert.True(t, index.Truncated)
+		sym, err := client.Symbol(context.Background(), "huge", "DefaultTimeout")
+		assert.NoError(t, err)
+		assert.True(t, sym.Truncated)
+		blocks, err := client.Values(context.Background(), "huge")
+		assert.ErrorIs(t, err, ErrDocTruncated)
+		assert.Len(t, blocks, 3)
+
+		pkg, err = client.DescribePackage(DescribePackageRequest{Package: "complete"})
+		assert.NoError(t, err)
+		assert.False(t, pkg.Truncated)
+		assert.Empty(t, pkg.TruncatedSections)
+		_, err = client.Values(context.Background(), "complete")
+		assert.NoError(t, err)
+	})
+}
+
+func TestClient_Stats(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/proxy/example.com/mod/@latest":
+			rw.Write([]byte(`{"Version":"v1.2.0"}`))
+		case r.URL.Path == "/proxy/example.com/mod/@v/v1.2.0.mod":
+			rw.Write([]byte("module example.com/mod\n\nrequire (\n\tgithub.com/a/a v1.0.0\n\tgithub.com/b/b v1.0.0\n\tgithub.com/c/c v1.0.0 // indirect\n)\n"))
+		case r.URL.Path == "/example.com/mod/pkg" && r.URL.Query().Get("tab") == "versions":
+			rw.Write([]byte(versionsPlainHTML))
+		case r.URL.Path == "/example.com/mod/pkg":
+			rw.Write([]byte(`<div class="UnitHeader-titleHeading">pkg</div><div>package</div>
+<a data-test-id="UnitHeader-importedby" href="?tab=importedby">Imported by: 1,234</a>` + genericDocHTML + `
+<ul class="Documentation-examplesList"><li><a href="#example-Max">Max</a></li></ul>`))
+		default:
+			rw.WriteHeader(404)
+		}
+	}, func(addr string) {
+		client := New(WithBaseURL("http://"+addr), WithProxyURL("http://"+addr+"/proxy"))
+		stats, err := client.Stats(context.Background(), "example.com/mod/pkg")
+		assert.NoError(t, err)
+		assert.Equal(t, &PackageStats{
+			ImportedByCount:   1234,
+			DirectDepsCount:   2,
+			IndirectDepsCount: 1,
+			VersionCount:      3,
+			ExampleCount:      1,
+			SymbolCount:       4,
+		}, stats)
+
+		_, err = client.Stats(context.Background(), "example.com/missing")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestClient_SearchPathPrefix(t *testing.T) {
+	pages := map[string][]string{
+		"1": {"github.com/myorganization/jwt", "github.com/other/jwt"},
+		"2": {"github.com/myorg/jwt", "github.com/myorg"},
+		"3": {"github.com/myorg/auth/jwt", "github.com/myorg/extra"},
+	}
+	var visited []string
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		visited = append(visited, page)
+		body := `<div class="SearchResults">`
+		for _, pkg := range pages[page] {
+			body += fmt.Sprintf(`<div class="SearchSnippet"><div class="SearchSnippet-headerContainer"><h2><a href="/%s">%s</a></h2></div></div>`, pkg, pkg)
+		}
+		rw.Write([]byte(body + `</div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+
+		results, err := client.Search(SearchRequest{Query: "jwt", Limit: 3, PathPrefix: "github.com/myorg/"})
+		assert.NoError(t, err)
+		var pkgs []string
+		for _, result := range results.Results {
+			pkgs = append(pkgs, result.Package)
+		}
+		assert.Equal(t, []string{"github.com/myorg/jwt", "github.com/myorg", "github.com/myorg/auth/jwt"}, pkgs)
+		assert.Equal(t, 6, results.Scanned)
+		assert.Equal(t, []string{"1", "2", "3"}, visited)
+
+		visited = nil
+		results, err = client.Search(SearchRequest{Query: "jwt", Limit: 10, MaxPages: 2, PathPrefix: "github.com/myorg"})
+		assert.NoError(t, err)
+		assert.Len(t, results.Results, 2)
+		assert.Equal(t, 4, results.Scanned)
+		assert.Equal(t, []string{"1", "2"}, visited)
+	})
+}
+
+func TestClient_WithAllowedHosts(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirected" {
+			_, port, _ := net.SplitHostPort(r.Host)
+			http.Redirect(rw, r, "http://localhost:"+port+"/somepackage", http.StatusFound)
+			return
+		}
+		rw.Write([]byte(`<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`))
+	}, func(addr string) {
+		allowed := New(WithBaseURL("http://"+addr), WithAllowedHosts("127.0.0.1"))
+		_, err := allowed.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+
+		var hostErr ErrHostNotAllowed
+		_, err = allowed.DescribePackage(DescribePackageRequest{Package: "redirected"})
+		assert.ErrorAs(t, err, &hostErr, "redirects are checked too")
+		assert.Equal(t, "localhost", hostErr.Host)
+
+		blocked := New(WithBaseURL("http://"+addr), WithAllowedHosts("pkg.go.dev"))
+		_, err = blocked.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+		assert.ErrorAs(t, err, &hostErr)
+		assert.Equal(t, "127.0.0.1", hostErr.Host)
+	})
+}
+
+func TestClient_Alternatives(t *testing.T) {
+	var queries []string
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" {
+			queries = append(queries, r.URL.Query().Get("q"))
+			if r.URL.Query().Get("page") != "1" {
+				rw.Write([]byte(`<div class="SearchResults"></div>`))
+				return
+			}
+			body := `<div class="SearchResults">`
+			for _, result := range []struct {
+				pkg        string
+				importedBy int
+			}{
+				{"github.com/foo/jwt", 500},
+				{"github.com/foo/jwt/v2/claims", 300},
+				{"github.com/bar/jwt", 10},
+				{"github.com/baz/token", 50},
+				{"github.com/qux/jose", 20},
+			} {
+				body += fmt.Sprintf(`<div class="SearchSnippet"><div class="SearchSnippet-headerContainer"><h2><a href="/%s">%s</a></h2></div>
+<div class="SearchSnippet-infoLabel"><a href="/%s?tab=importedby">Imported by <strong>%d</strong></a></div></div>`, result.pkg, result.pkg, result.pkg, result.importedBy)
+			}
+			rw.Write([]byte(body + `</div>`))
+			return
+		}
+		rw.Write([]byte(`<div class="UnitHeader-titleHeading">jwt</div><div>package</div>
+<div class="UnitHeader-majorVersions"><a href="/github.com/foo/jwt">v1</a><a href="/github.com/foo/jwt/v2">v2</a></div>
+<div class="Documentation-overview"><p>Package jwt implements JSON Web Tokens, for the JWT spec.</p></div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		alternatives, err := client.Alternatives("github.com/foo/jwt/v2", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "jwt json web tokens", queries[0])
+		var pkgs []string
+		for _, result := range alternatives {
+			pkgs = append(pkgs, result.Package)
+		}
+		assert.Equal(t, []string{"github.com/baz/token", "github.com/qux/jose"}, pkgs)
+
+		_, err = client.Alternatives("github.com/foo/jwt/v2", 0)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+}
+
+func TestVersions_ReleaseStats(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	versions := &Versions{Versions: []Version{
+		{FullVersion: "v1.3.0", Date: "2024-05-01"},
+		{FullVersion: "v1.2.1", Date: "2024-03-01", Retracted: true},
+		{FullVersion: "v0.0.0-20240201000000-abcdefabcdef", Date: "2024-02-01"},
+		{FullVersion: "v1.2.0", Date: "2024-01-01"},
+		{FullVersion: "v1.1.0", Date: "2023-01-01"},
+		{FullVersion: "v1.0.0", Date: "2022-12-22"},
+		{FullVersion: "v0.9.0", Date: "sometime"},
+	}}
+	day := 24 * time.Hour
+
+	stats := versions.ReleaseStats(false, now)
+	assert.Equal(t, 4, stats.Releases)
+	assert.Equal(t, 1, stats.Excluded)
+	assert.Equal(t, time.Date(2022, 12, 22, 0, 0, 0, 0, time.UTC), stats.FirstRelease)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), stats.LastRelease)
+	assert.Equal(t, 31*day, stats.SinceLast)
+	assert.Equal(t, 2, stats.ReleasesLastYear)
+	// intervals of 10, 121 and 365 days
+	assert.Equal(t, 121*day, stats.MedianInterval)
+
+	withPseudo := versions.ReleaseStats(true, now)
+	assert.Equal(t, 5, withPseudo.Releases)
+	// intervals of 10, 31, 365 and 90 days
+	assert.Equal(t, (31*day+90*day)/2, withPseudo.MedianInterval)
+
+	single := (&Versions{Versions: []Version{{FullVersion: "v1.0.0", Date: "2024-01-01"}}}).ReleaseStats(false, now)
+	assert.Equal(t, 1, single.Releases)
+	assert.Zero(t, single.MedianInterval)
+	assert.Equal(t, VersionStats{}, (&Versions{}).ReleaseStats(false, now))
+}
+
+func TestClient_VersionsRetracted(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<html><body><div class="Versions-list">
+  <div class="Version-major">v1</div>
+  <div class="Version-tag"><a class="js-versionLink" href="/somepackage@v1.0.1">v1.0.1</a><span class="go-Chip">retracted</span></div>
+  <div class="Version-commitTime">Jan 3, 2020</div>
+  <div class="Version-tag"><a class="js-versionLink" href="/somepackage@v1.0.0">v1.0.0</a></div>
+  <div class="Version-commitTime">Jan 2, 2020</div>
+</div></body></html>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		client.clock = func() time.Time { return time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC) }
+		versions, err := client.Versions(VersionsRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.True(t, versions.Versions[0].Retracted)
+		assert.Equal(t, "v1.0.1", versions.Versions[0].FullVersion)
+		assert.False(t, versions.Versions[1].Retracted)
+		stats := versions.ReleaseStats(false, versions.Response.FetchedAt)
+		assert.Equal(t, 1, stats.Releases)
+		assert.Equal(t, 8*24*time.Hour, stats.SinceLast)
+	})
+}
+
+func TestClient_VersionsUseGOPROXY(t *testing.T) {
+	var scraped bool
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/proxy/github.com/!foo/bar/@latest":
+			rw.Write([]byte(`{"Version":"v1.10.0"}`))
+		case "/proxy/github.com/!foo/bar/@v/list":
+			rw.Write([]byte("v1.2.0\nv1.10.0\nv1.9.0-rc.1\n"))
+		case "/proxy/github.com/!foo/bar/@v/v1.2.0.info":
+			rw.Write([]byte(`{"Version":"v1.2.0","Time":"2021-01-02T10:00:00Z"}`))
+		case "/proxy/github.com/!foo/bar/@v/v1.10.0.info":
+			rw.Write([]byte(`{"Version":"v1.10.0","Time":"2023-05-06T10:00:00Z"}`))
+		case "/proxy/github.com/!foo/bar/@v/v1.9.0-rc.1.info":
+			rw.Write([]byte(`{"Version":"v1.9.0-rc.1","Time":"2022-03-04T10:00:00Z"}`))
+		default:
+			if !strings.HasPrefix(r.URL.Path, "/proxy/") {
+				scraped = true
+			}
+			rw.WriteHeader(404)
+		}
+	}, func(addr string) {
+		client := New(WithBaseURL("http://"+addr), WithProxyURL("http://"+addr+"/proxy"))
+		versions, err := client.Versions(VersionsRequest{Package: "github.com/Foo/bar/baz", UseGOPROXY: true})
+		assert.NoError(t, err)
+		assert.False(t, scraped)
+		assert.Equal(t, []Version{
+			{MajorVersion: "v1", FullVersion: "v1.10.0", Date: "2023-05-06"},
+			{MajorVersion: "v1", FullVersion: "v1.9.0-rc.1", Date: "2022-03-04"},
+			{MajorVersion: "v1", FullVersion: "v1.2.0", Date: "2021-01-02"},
+		}, versions.Versions)
+		assert.Equal(t, "github.com/Foo/bar/baz", versions.Package)
+
+		_, err = client.Versions(VersionsRequest{Package: "example.com/missing", UseGOPROXY: true})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestPackage_RequireDirective(t *testing.T) {
+	cases := []struct {
+		name          string
+		pkg           Package
+		expectRequire string
+		expectGoGet   string
+		expectErr     bool
+	}{
+		{
+			name:          "v0",
+			pkg:           Package{Package: "example.com/mod/pkg", Version: "v0.3.1", MajorVersions: []string{"example.com/mod"}},
+			expectRequire: "require example.com/mod v0.3.1",
+			expectGoGet:   "go get example.com/mod/pkg@v0.3.1",
+		},
+		{
+			name:          "major version suffix",
+			pkg:           Package{Package: "github.com/foo/bar/v2/baz", Version: "v2.1.0", MajorVersions: []string{"github.com/foo/bar", "github.com/foo/bar/v2"}},
+			expectRequire: "require github.com/foo/bar/v2 v2.1.0",
+			expectGoGet:   "go get github.com/foo/bar/v2/baz@v2.1.0",
+		},
+		{
+			name:          "incompatible",
+			pkg:           Package{Package: "github.com/foo/legacy/sub", Version: "v3.2.0"},
+			expectRequire: "require github.com/foo/legacy v3.2.0+incompatible",
+			expectGoGet:   "go get github.com/foo/legacy/sub@v3.2.0+incompatible",
+		},
+		{
+			name:          "already incompatible",
+			pkg:           Package{Package: "github.com/foo/legacy", Version: "v3.2.0+incompatible"},
+			expectRequire: "require github.com/foo/legacy v3.2.0+incompatible",
+			expectGoGet:   "go get github.com/foo/legacy@v3.2.0+incompatible",
+		},
+		{
+			name:          "gopkg.in",
+			pkg:           Package{Package: "gopkg.in/yaml.v3", Version: "v3.0.1"},
+			expectRequire: "require gopkg.in/yaml.v3 v3.0.1",
+			expectGoGet:   "go get gopkg.in/yaml.v3@v3.0.1",
+		},
+		{
+			name:      "mismatched major",
+			pkg:       Package{Package: "github.com/foo/bar/v2", Version: "v3.0.0"},
+			expectErr: true,
+		},
+		{
+			name:      "abbreviated pseudo-version",
+			pkg:       Package{Package: "github.com/foo/bar", Version: "v0.0.0-...-496545a"},
+			expectErr: true,
+		},
+		{
+			name:      "no version",
+			pkg:       Package{Package: "github.com/foo/bar"},
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require, err := c.pkg.RequireDirective()
+			goGet, goGetErr := c.pkg.GoGetCommand()
+			if c.expectErr {
+				assert.Error(t, err)
+				assert.Error(t, goGetErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, goGetErr)
+			assert.Equal(t, c.expectRequire, require)
+			assert.Equal(t, c.expectGoGet, goGet)
+		})
+	}
+
+	v := &Version{FullVersion: "v2.0.0"}
+	assert.Equal(t, "require github.com/foo/bar/v2 v2.0.0", v.RequireDirective("github.com/foo/bar/v2"))
+	assert.Equal(t, "require github.com/foo/bar v2.0.0+incompatible", v.RequireDirective("github.com/foo/bar"))
+	v = &Version{FullVersion: "v1.5.0"}
+	assert.Equal(t, "require github.com/foo/bar v1.5.0", v.RequireDirective("github.com/foo/bar"))
+}
+
+func TestClient_FetchGoMod(t *testing.T) {
+	const goMod = "module github.com/Foo/bar\n\ngo 1.21\n\nrequire golang.org/x/mod v0.25.0\n"
+	var requests int
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/proxy/github.com/!foo/bar/@v/v1.2.0.mod":
+			rw.Write([]byte(goMod))
+		case "/proxy/github.com/!foo/bar/@v/v1.3.0.mod":
+			rw.Write([]byte("<html>not a go.mod</html>"))
+		default:
+			rw.WriteHeader(404)
+		}
+	}, func(addr string) {
+		client := New(WithProxyURL("http://"+addr+"/proxy"), WithCache(time.Minute))
+
+		for i := 0; i < 2; i++ {
+			text, err := client.FetchGoMod(context.Background(), "github.com/Foo/bar", "v1.2.0")
+			assert.NoError(t, err)
+			assert.Equal(t, goMod, text)
+		}
+		assert.Equal(t, 1, requests, "the second fetch is served from the cache")
+
+		_, err := client.FetchGoMod(context.Background(), "github.com/Foo/bar", "v1.3.0")
+		assert.ErrorIs(t, err, ErrUnexpectedContent)
+		_, err = client.FetchGoMod(context.Background(), "github.com/Foo/bar", "v9.9.9")
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = client.FetchGoMod(context.Background(), "github.com/Foo/bar", "master")
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+
+		requests = 0
+		client = New(WithProxyURL("http://"+addr+"/proxy"), WithPkgGoDevOnly())
+		_, err = client.FetchGoMod(context.Background(), "github.com/Foo/bar", "v1.2.0")
+		assert.True(t, errors.Is(err, ErrExternalFetchDisabled), err)
+		assert.Zero(t, requests)
+	})
+}
+
+func TestClient_Warnings(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/search":
+			if r.URL.Query().Get("page") != "1" {
+				rw.Write([]byte(`<div class="SearchResults"></div>`))
+				return
+			}
+			rw.Write([]byte(`<div class="SearchResults"><div class="SearchSnippet">
+<div class="SearchSnippet-headerContainer"><h2><a href="/github.com/foo/bar">github.com/foo/bar</a></h2></div>
+<div class="SearchSnippet-infoLabel">
+  <span data-test-id="snippet-published"><strong>3 fortnights ago</strong></span>
+  <a href="/github.com/foo/bar?tab=importedby">Imported by <strong>many</strong></a>
+</div></div></div>`))
+		case r.URL.Query().Get("tab") == "versions":
+			rw.Write([]byte(`<div class="Versions-list">
+  <div class="Version-tag"><a class="js-versionLink">v1.0.1</a></div>
+  <div class="Version-details"><summary class="Version-summary">Smarch 40, 2020</summary></div>
+  <div class="Version-tag"><a class="js-versionLink">v1.0.0</a></div>
+  <div class="Version-commitTime">Jan 2, 2020</div>
+</div>`))
+		default:
+			rw.Write([]byte(`<div class="UnitHeader-titleHeading">Heading</div><div>package</div>
+<div data-test-id="UnitHeader-commitTime">  Published: February 333, 20 </div>
+<div class="UnitMeta"><ul>
+  <li><img alt="checked"/></li>
+  <li><img alt="checked"/></li>
+  <li><img alt="checked"/></li>
+  <li><img alt="checked"/></li>
+  <li>Reproducible build</li>
+</ul></div>`))
+		}
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		fieldErrors := func(warnings []error) []FieldError {
+			var fields []FieldError
+			for _, warning := range warnings {
+				var fieldErr *FieldError
+				if assert.ErrorAs(t, warning, &fieldErr) {
+					fields = append(fields, FieldError{Field: fieldErr.Field, Raw: fieldErr.Raw})
+				}
+			}
+			return fields
+		}
+
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.True(t, pkg.HasStableVersion)
+		assert.Empty(t, pkg.Published)
+		assert.Equal(t, []FieldError{
+			{Field: "UnitMeta", Raw: "Reproducible build"},
+			{Field: "Published", Raw: "February 333, 20"},
+		}, fieldErrors(pkg.Warnings))
+
+		versions, err := client.Versions(VersionsRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.Equal(t, []Version{
+			{FullVersion: "v1.0.1"},
+			{FullVersion: "v1.0.0", Date: "2020-01-02"},
+		}, versions.Versions)
+		assert.Equal(t, []FieldError{{Field: "Date", Raw: "Smarch 40, 2020"}}, fieldErrors(versions.Warnings))
+		assert.ErrorContains(t, versions.Warnings[0], "parsing date of version 'v1.0.1'")
+
+		results, err := client.Search(SearchRequest{Query: "bar", Limit: 10})
+		assert.NoError(t, err)
+		assert.Len(t, results.Results, 1)
+		assert.Equal(t, []FieldError{
+			{Field: "Published", Raw: "3 fortnights ago"},
+			{Field: "ImportedBy", Raw: "many"},
+			// without a version span, the published text is taken as the version
+			{Field: "Version", Raw: "3 fortnights ago"},
+		}, fieldErrors(results.Warnings))
+	})
+}
+
+// gopkgInUnitHTML is a unit page of a gopkg.in package whose repository link
+// points back at gopkg.in
+const gopkgInUnitHTML = `<html><body>
+<div data-test-id="UnitHeader-version"><a>Version: %s</a></div>
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div>
+<div class="UnitMeta-repo"><a>%s</a></div>
+</body></html>`
+
+func TestClient_GopkgIn(t *testing.T) {
+	t.Run("DescribePackage", func(t *testing.T) {
+		cases := []struct {
+			pkg, version, repo string
+			expectRepo         string
+			expectModule       string
+		}{
+			{pkg: "gopkg.in/yaml.v3", version: "v3.0.1", repo: "gopkg.in/yaml.v3", expectRepo: "github.com/go-yaml/yaml", expectModule: "v3"},
+			{pkg: "gopkg.in/mgo.v2/bson", version: "v2.0.0-20190816093944-a6b53ec6cb22", repo: "", expectRepo: "github.com/go-mgo/mgo", expectModule: "v2"},
+			{pkg: "gopkg.in/yaml.v3", version: "v3.0.1", repo: "github.com/go-yaml/yaml", expectRepo: "github.com/go-yaml/yaml", expectModule: "v3"},
+		}
+		for _, c := range cases {
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(rw, gopkgInUnitHTML, c.version, c.repo)
+			}, func(addr string) {
+				client := New(WithBaseURL("http://" + addr))
+				pkg, err := client.DescribePackage(DescribePackageRequest{Package: c.pkg})
+				assert.NoError(t, err)
+				assert.Equal(t, c.expectRepo, pkg.Repository)
+				assert.Equal(t, c.expectModule, pkg.ModuleVersion)
+				assert.True(t, pkg.IsModuleV2Plus())
+				assert.Equal(t, []string{strings.TrimSuffix(c.pkg, "/bson")}, pkg.MajorVersions)
+			})
+		}
+	})
+
+	t.Run("Versions", func(t *testing.T) {
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			rw.Write([]byte(`<html><body><div class="UnitMeta-repo"><a>gopkg.in/yaml.v3</a></div>
+<div class="Versions-list">
+  <div class="Version-major">gopkg.in/yaml.v3</div>
+  <div class="Version-tag"><a class="js-versionLink" href="/gopkg.in/yaml.v3@v3.0.1">v3.0.1</a></div>
+  <div class="Version-commitTime">May 27, 2022</div>
+  <div class="Version-major"></div>
+  <div class="Version-tag"><a class="js-versionLink" href="/gopkg.in/yaml.v3@v3.0.0">v3.0.0</a></div>
+  <div class="Version-commitTime">May 26, 2022</div>
+</div></body></html>`))
+		}, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			versions, err := client.Versions(VersionsRequest{Package: "gopkg.in/yaml.v3"})
+			assert.NoError(t, err)
+			assert.Equal(t, "github.com/go-yaml/yaml", versions.Repository)
+			if assert.Len(t, versions.Versions, 2) {
+				for _, v := range versions.Versions {
+					assert.Equal(t, "v3", v.MajorVersion)
+					assert.Equal(t, "github.com/go-yaml/yaml", v.Repository)
+				}
+			}
+		})
+
+		// without headings the major comes from the path
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			rw.Write([]byte(`<html><body><div class="Versions-list">
+  <div class="Version-tag"><a class="js-versionLink" href="/gopkg.in/mgo.v2@v2.0.0-20190816093944-a6b53ec6cb22">v2.0.0-20190816093944-a6b53ec6cb22</a></div>
+  <div class="Version-commitTime">Aug 16, 2019</div>
+</div></body></html>`))
+		}, func(addr string) {
+			client := New(WithBaseURL("http://" + addr))
+			versions, err := client.Versions(VersionsRequest{Package: "gopkg.in/mgo.v2"})
+			assert.NoError(t, err)
+			assert.Equal(t, "github.com/go-mgo/mgo", versions.Repository)
+			if assert.Len(t, versions.Versions, 1) {
+				assert.Equal(t, "v2", versions.Versions[0].MajorVersion)
+			}
+		})
+	})
+
+	t.Run("Sprinkle", func(t *testing.T) {
+		for _, c := range []struct{ pkg, repo, expectPath string }{
+			{pkg: "gopkg.in/yaml.v3", repo: "gopkg.in/yaml.v3", expectPath: "/go-yaml/yaml"},
+			{pkg: "gopkg.in/mgo.v2", repo: "https://gopkg.in/mgo.v2", expectPath: "/go-mgo/mgo"},
+		} {
+			var mu sync.Mutex
+			var requested []string
+			withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+				rw.Write([]byte(`<html><body><p class="f4 my-3">Some description</p></body></html>`))
+			}, func(addr string) {
+				// send the requests for github.com to the test server
+				transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					mu.Lock()
+					requested = append(requested, r.URL.Host+r.URL.Path)
+					mu.Unlock()
+					r = r.Clone(r.Context())
+					r.URL.Scheme, r.URL.Host = "http", addr
+					return http.DefaultTransport.RoundTrip(r)
+				})
+				client := New(WithHTTPClient(&http.Client{Transport: transport}))
+				p := &Package{Package: c.pkg, Repository: c.repo}
+				assert.NoError(t, client.Sprinkle(p))
+				assert.Equal(t, "Some description", p.Synopsis)
+				assert.Equal(t, []string{"github.com" + c.expectPath}, requested)
+			})
+		}
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// withSOCKS5Proxy runs a minimal SOCKS5 proxy connecting every CONNECT to
+// target, recording the requested addresses
+func withSOCKS5Proxy(target string, f func(addr string, requested func() []string)) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	defer listener.Close()
+	var mu sync.Mutex
+	var requested []string
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				// greeting: version, method count, methods
+				buf := make([]byte, 262)
+				if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+					return
+				}
+				if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+					return
+				}
+				conn.Write([]byte{5, 0})
+				// request: version, command, reserved, address type
+				if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+					return
+				}
+				var host string
+				switch buf[3] {
+				case 1:
+					io.ReadFull(conn, buf[:4])
+					host = net.IP(buf[:4]).String()
+				case 3:
+					io.ReadFull(conn, buf[:1])
+					n := int(buf[0])
+					io.ReadFull(conn, buf[:n])
+					host = string(buf[:n])
+				default:
+					return
+				}
+				io.ReadFull(conn, buf[:2])
+				mu.Lock()
+				requested = append(requested, fmt.Sprintf("%s:%d", host, int(buf[0])<<8|int(buf[1])))
+				mu.Unlock()
+
+				upstream, err := net.Dial("tcp", target)
+				if err != nil {
+					conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer upstream.Close()
+				conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				go io.Copy(upstream, conn)
+				io.Copy(conn, upstream)
+			}()
+		}
+	}()
+
+	f(listener.Addr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requested...)
+	})
+}
+
+func TestClient_WithTorProxy(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<div class="u-breakWord">foo</div>`))
+	}, func(addr string) {
+		withSOCKS5Proxy(addr, func(proxyAddr string, requested func() []string) {
+			// the host name only resolves through the proxy
+			client := New(WithBaseURL("http://pkg.go.dev.invalid"), WithTorProxy(proxyAddr), WithTimeout(time.Minute))
+			importedBy, err := client.ImportedBy(ImportedByRequest{Package: "somepackage"})
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"foo"}, importedBy.ImportedBy)
+			assert.Equal(t, []string{"pkg.go.dev.invalid:80"}, requested())
+
+			// a custom round tripper would bypass the proxy, so it's replaced
+			bypass := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return nil, errors.New("bypassed the proxy")
+			})
+			client = New(WithBaseURL("http://pkg.go.dev.invalid"), WithTorProxy("socks5://"+proxyAddr),
+				WithHTTPClient(&http.Client{Transport: bypass}), WithCache(time.Minute))
+			_, err = client.ImportedBy(ImportedByRequest{Package: "somepackage"})
+			assert.NoError(t, err)
+			assert.Len(t, requested(), 2)
+		})
+	})
+
+	c := New(WithTorProxy(""))
+	assert.Equal(t, defaultTorProxyURL, c.torProxy.String())
+}
+
+func TestClient_DownloadImages(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			http.NotFound(rw, r)
+			return
+		}
+		rw.Header().Set("Content-Type", "image/png")
+		rw.Write([]byte("png:" + r.URL.Path))
+	}, func(addr string) {
+		base := "http://" + addr
+		images := ImageSet{
+			{Alt: "Build Status", URL: base + "/build.svg"},
+			{Alt: "badge", URL: base + "/one.png"},
+			{Alt: "badge", URL: base + "/two.png"},
+			{Alt: "", URL: base + "/logo"},
+			{Alt: "gone", URL: base + "/missing.png"},
+		}
+		dir := filepath.Join(t.TempDir(), "images")
+		saved, err := New().DownloadImages(context.Background(), images, dir)
+		var errs *ErrorList
+		if assert.ErrorAs(t, err, &errs) && assert.Len(t, errs.Errs, 1) {
+			assert.ErrorIs(t, errs.Errs[0], ErrUnexpectedStatus)
+		}
+		assert.Equal(t, map[string]string{
+			"Build Status": filepath.Join(dir, "Build_Status.svg"),
+			"badge":        filepath.Join(dir, "badge.png"),
+			"badge-1":      filepath.Join(dir, "badge-1.png"),
+			"image":        filepath.Join(dir, "image"),
+		}, saved)
+		for _, file := range saved {
+			content, err := os.ReadFile(file)
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(string(content), "png:/"))
+		}
+		_, err = os.Stat(filepath.Join(dir, "gone.png"))
+		assert.True(t, os.IsNotExist(err), "failed downloads leave no file")
+	})
+}
+
+func TestClient_DescribePackageMinimalOK(t *testing.T) {
+	const header = `<html><body>
+<div data-test-id="UnitHeader-version"><a>Version: v1.2.0</a></div>
+<div data-test-id="UnitHeader-licenses"><a>MIT</a></div>
+`
+	// the checklist lost rows and the title badges aren't recognized
+	const broken = `<div data-test-id="UnitHeader-commitTime">Published: Feb 3, 2021</div>
+<div class="UnitMeta"><ul>
+  <li><img alt="checked"/></li>
+  <li><img alt="checked"/></li>
+</ul></div>
+<div class="UnitHeader-titleHeading">Heading</div><div>something new</div>
+</body></html>`
+
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/noheader") {
+			rw.Write([]byte(`<html><body>` + broken))
+			return
+		}
+		rw.Write([]byte(header + broken))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+
+		_, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+		assert.ErrorIs(t, err, errIncompleteChecklist)
+		assert.ErrorContains(t, err, "IsPackage=false")
+
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage", MinimalOK: true})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "v1.2.0", pkg.Version)
+			assert.Equal(t, "MIT", pkg.License)
+			assert.Equal(t, "2021-02-03", pkg.Published)
+			if assert.Len(t, pkg.Warnings, 2) {
+				var fieldErr *FieldError
+				if assert.ErrorAs(t, pkg.Warnings[0], &fieldErr) {
+					assert.Equal(t, "UnitMeta", fieldErr.Field)
+				}
+				assert.ErrorIs(t, pkg.Warnings[0], errIncompleteChecklist)
+				assert.ErrorContains(t, pkg.Warnings[1], "IsPackage=false")
+			}
+		}
+
+		// the minimal set itself must parse
+		_, err = client.DescribePackage(DescribePackageRequest{Package: "noheader", MinimalOK: true})
+		var errs *ErrorList
+		if assert.ErrorAs(t, err, &errs) {
+			var fields []string
+			for _, e := range errs.Errs {
+				var fieldErr *FieldError
+				if errors.As(e, &fieldErr) {
+					fields = append(fields, fieldErr.Field)
+				}
+			}
+			assert.Equal(t, []string{"Version", "License", "UnitMeta"}, fields)
+		}
+	})
+}
+
+func TestClient_ComparePackages(t *testing.T) {
+	var mu sync.Mutex
+	unitRequests := map[string]int{}
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery == "" {
+			mu.Lock()
+			unitRequests[r.URL.Path]++
+			mu.Unlock()
+		}
+		switch {
+		case r.URL.Path == "/proxy/example.com/a/@latest", r.URL.Path == "/proxy/example.com/b/@latest":
+			rw.Write([]byte(`{"Version":"v1.0.0"}`))
+		case r.URL.Path == "/proxy/example.com/a/@v/v1.0.0.mod":
+			rw.Write([]byte("module example.com/a\n\nrequire github.com/x/x v1.0.0\n"))
+		case r.URL.Path == "/proxy/example.com/b/@v/v1.0.0.mod":
+			rw.Write([]byte("module example.com/b\n"))
+		case r.URL.Query().Get("tab") == "versions":
+			rw.Write([]byte(versionsPlainHTML))
+		case r.URL.Path == "/example.com/a":
+			rw.Write([]byte(`<div class="UnitHeader-titleHeading">a</div><div>package</div>
+<div data-test-id="UnitHeader-commitTime">Published: Feb 3, 2021</div>
+<a data-test-id="UnitHeader-importedby">Imported by: 50</a>
+<div class="UnitMeta"><ul>
+  <li><img alt="checked"/></li><li><img alt="checked"/></li><li><img alt="checked"/></li><li><img alt="checked"/></li>
+</ul></div>`))
+		case r.URL.Path == "/example.com/b", r.URL.Path == "/example.com/nomod":
+			rw.Write([]byte(`<div class="UnitHeader-titleHeading">b</div><div>package</div>
+<div data-test-id="UnitHeader-commitTime">Published: Mar 3, 2022</div>
+<a data-test-id="UnitHeader-importedby">Imported by: 10</a>
+<div class="UnitMeta"><ul>
+  <li><img alt="checked"/></li><li><img alt="checked"/></li><li><img alt="checked"/></li><li><img alt="unchecked"/></li>
+</ul></div>`))
+		default:
+			rw.WriteHeader(404)
+		}
+	}, func(addr string) {
+		client := New(WithBaseURL("http://"+addr), WithProxyURL("http://"+addr+"/proxy"))
+		comparison, err := client.ComparePackages(context.Background(), "example.com/a", "example.com/b")
+		assert.NoError(t, err)
+		assert.Equal(t, []MetricOutcome{
+			{Metric: "published", Winner: "example.com/b"},
+			{Metric: "stable version", Winner: "example.com/a"},
+			{Metric: "valid go.mod"},
+			{Metric: "redistributable license"},
+			{Metric: "documented symbols"},
+			{Metric: "imported by", Winner: "example.com/a"},
+			{Metric: "versions"},
+			{Metric: "dependencies", Winner: "example.com/b"},
+		}, comparison.Metrics)
+		assert.Equal(t, 2, comparison.AWins)
+		assert.Equal(t, 2, comparison.BWins)
+		assert.Equal(t, 4, comparison.Ties)
+		assert.Equal(t, "example.com/a", comparison.A.Package)
+		assert.Equal(t, 50, comparison.AStats.ImportedByCount)
+		assert.Equal(t, 1, unitRequests["/example.com/a"], "unit pages are fetched once")
+		assert.Equal(t, 1, unitRequests["/example.com/b"], "unit pages are fetched once")
+
+		// without go.mod the stats based metrics are skipped
+		comparison, err = client.ComparePackages(context.Background(), "example.com/a", "example.com/nomod")
+		var errs *ErrorList
+		assert.ErrorAs(t, err, &errs)
+		if assert.NotNil(t, comparison) {
+			assert.Len(t, comparison.Metrics, 5)
+			assert.Equal(t, 1, comparison.AWins)
+			assert.Equal(t, 1, comparison.BWins)
+		}
+
+		comparison, err = client.ComparePackages(context.Background(), "example.com/a", "example.com/missing")
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Nil(t, comparison)
+	})
+}
+
+func TestClient_SplitPagePath(t *testing.T) {
+	client := New(WithBaseURL("http://localhost:8080"))
+	cases := []struct {
+		input, expectPath, expectVersion string
+	}{
+		{input: "github.com/foo/bar", expectPath: "github.com/foo/bar"},
+		{input: " https://pkg.go.dev/github.com/foo/bar#Section ", expectPath: "github.com/foo/bar"},
+		{input: "https://pkg.go.dev/github.com/foo/bar?tab=doc", expectPath: "github.com/foo/bar"},
+		{input: "pkg.go.dev/github.com/foo/bar/", expectPath: "github.com/foo/bar"},
+		{input: "http://localhost:8080/github.com/foo/bar?tab=versions#v1", expectPath: "github.com/foo/bar"},
+		{input: "https://pkg.go.dev/github.com/foo/bar@v1.2.0/baz?tab=doc#Func", expectPath: "github.com/foo/bar/baz", expectVersion: "v1.2.0"},
+		{input: "github.com/foo/bar@master", expectPath: "github.com/foo/bar", expectVersion: "master"},
+	}
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			path, version := client.splitPagePath(c.input)
+			assert.Equal(t, c.expectPath, path)
+			assert.Equal(t, c.expectVersion, version)
+		})
+	}
+}
+
+func TestClient_PkgGoDevURLInput(t *testing.T) {
+	var requested []string
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		rw.Write([]byte(`<html><body><div data-test-id="UnitHeader-version"><a>Version: v1.2.0</a></div>
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div>
+<div class="u-breakWord">foo</div></body></html>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "https://pkg.go.dev/github.com/foo/bar@v1.2.0/baz#Section"})
+		assert.NoError(t, err)
+		assert.Equal(t, "github.com/foo/bar/baz", pkg.Package)
+		assert.Equal(t, "v1.2.0", pkg.RequestedVersion)
+
+		versions, err := client.Versions(VersionsRequest{Package: "http://" + addr + "/github.com/foo/bar?tab=versions"})
+		assert.NoError(t, err)
+		assert.Equal(t, "github.com/foo/bar", versions.Package)
+
+		importedBy, err := client.ImportedBy(ImportedByRequest{Package: "github.com/foo/bar?tab=importedby"})
+		assert.NoError(t, err)
+		assert.Equal(t, "github.com/foo/bar", importedBy.Package)
+
+		assert.Equal(t, []string{
+			"/github.com/foo/bar/baz@v1.2.0",
+			"/github.com/foo/bar?tab=versions",
+			"/github.com/foo/bar?tab=importedby",
+		}, requested)
+	})
+}
+
+func TestVersion_PublishedTime(t *testing.T) {
+	published, err := Version{FullVersion: "v1.0.0", Date: "2021-02-03"}.PublishedTime()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, time.February, 3, 0, 0, 0, 0, time.UTC), published)
+
+	_, err = Version{FullVersion: "v1.0.1", Date: "Smarch 40"}.PublishedTime()
+	assert.ErrorContains(t, err, "v1.0.1")
+
+	published, err = Version{FullVersion: "v1.0.1"}.PublishedTime()
+	assert.ErrorIs(t, err, ErrNilDate)
+	assert.True(t, published.IsZero())
+}
+
+func TestPackage_PublishedTime(t *testing.T) {
+	published, err := (&Package{Published: "2021-02-03"}).PublishedTime()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, time.February, 3, 0, 0, 0, 0, time.UTC), published)
+
+	published, err = (&Package{Package: "somepackage"}).PublishedTime()
+	assert.ErrorIs(t, err, ErrNilDate)
+	assert.True(t, published.IsZero())
+}
+
+func TestClient_ResponseMeta(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/github.com/Foo/bar":
+			// pkg.go.dev redirects to the canonical path
+			http.Redirect(rw, r, "/github.com/foo/bar?"+r.URL.RawQuery, http.StatusFound)
+		case r.URL.Path == "/search":
+			if r.URL.Query().Get("page") == "1" {
+				rw.Write([]byte(`<div class="SearchResults"><div class="SearchSnippet"><div class="SearchSnippet-headerContainer"><h2><a href="/foo">foo</a></h2></div></div></div>`))
+				return
+			}
+			rw.Write([]byte(`<div class="SearchResults"></div>`))
+		default:
+			rw.Write([]byte(`<html><body><div class="UnitHeader-titleHeading">Heading</div><div>package</div></body></html>`))
+		}
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		before := time.Now()
+
+		pkg, err := client.DescribePackage(DescribePackageRequest{Package: "github.com/Foo/bar"})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, pkg.Response.StatusCode)
+		assert.Equal(t, "http://"+addr+"/github.com/foo/bar?", pkg.Response.FinalURL)
+		assert.False(t, pkg.Response.FetchedAt.Before(before))
+
+		versions, err := client.Versions(VersionsRequest{Package: "github.com/Foo/bar"})
+		assert.NoError(t, err)
+		assert.Equal(t, "http://"+addr+"/github.com/foo/bar?tab=versions", versions.Response.FinalURL)
+
+		importedBy, err := client.ImportedBy(ImportedByRequest{Package: "github.com/foo/bar"})
+		assert.NoError(t, err)
+		assert.Equal(t, "http://"+addr+"/github.com/foo/bar?tab=importedby", importedBy.Response.FinalURL)
+
+		results, err := client.Search(SearchRequest{Query: "foo", Limit: 10})
+		assert.NoError(t, err)
+		if assert.Len(t, results.Responses, 2) {
+			assert.Equal(t, "http://"+addr+"/search?q=foo&page=1", results.Responses[0].FinalURL)
+			assert.Equal(t, "http://"+addr+"/search?q=foo&page=2", results.Responses[1].FinalURL)
+		}
+		assert.Equal(t, results.Responses, results.Filter(func(SearchResult) bool { return false }).Responses)
+	})
+}
+
+func TestClient_DescribeModule(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/proxy/example.com/mod/@latest":
+			rw.Write([]byte(`{"Version":"v1.2.0"}`))
+		case "/example.com/mod":
+			rw.Write([]byte(`<html><body><div class="UnitHeader-titleHeading">mod</div><div>module</div>
+<div data-test-id="UnitHeader-version"><a>Version: v1.2.0</a></div>
+<div class="UnitMeta-repo"><a>github.com/example/mod</a></div></body></html>`))
+		case "/example.com/mod/sub":
+			rw.Write([]byte(`<html><body><div class="UnitHeader-titleHeading">sub</div><div>package</div>
+<div class="UnitHeader-majorVersions"><a href="/example.com/mod">v1</a><a href="/example.com/mod/v2">v2</a></div></body></html>`))
+		default:
+			rw.WriteHeader(404)
+		}
+	}, func(addr string) {
+		client := New(WithBaseURL("http://"+addr), WithProxyURL("http://"+addr+"/proxy"))
+		for _, path := range []string{"example.com/mod", "example.com/mod/sub", "example.com/mod/cmd/tool", "https://pkg.go.dev/example.com/mod/sub#section"} {
+			t.Run(path, func(t *testing.T) {
+				pkg, err := client.DescribeModule(context.Background(), path)
+				if assert.NoError(t, err) {
+					assert.Equal(t, "example.com/mod", pkg.Package)
+					assert.True(t, pkg.IsModule)
+					assert.Equal(t, "github.com/example/mod", pkg.Repository)
+				}
+			})
+		}
+
+		// without the proxy the module comes from the unit header
+		mu.Lock()
+		requested = nil
+		mu.Unlock()
+		client = New(WithBaseURL("http://"+addr), WithPkgGoDevOnly())
+		pkg, err := client.DescribeModule(context.Background(), "example.com/mod/sub")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "example.com/mod", pkg.Package)
+		}
+		assert.Equal(t, []string{"/example.com/mod/sub", "/example.com/mod"}, requested)
+	})
+}
+
+func TestVersions_TimeRange(t *testing.T) {
+	versions := &Versions{Versions: []Version{
+		{FullVersion: "v1.2.0", Date: "2022-03-01"},
+		{FullVersion: "v1.1.0", Date: "2021-06-15"},
+		{FullVersion: "v1.0.1"},
+		{FullVersion: "v1.0.0", Date: "2021-01-01"},
+	}}
+	fullVersions := func(vs []Version) []string {
+		names := []string{}
+		for _, v := range vs {
+			names = append(names, v.FullVersion)
+		}
+		return names
+	}
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	assert.Equal(t, []string{"v1.2.0", "v1.1.0"}, fullVersions(versions.Since(day(2021, time.June, 15))))
+	assert.Equal(t, []string{"v1.0.0"}, fullVersions(versions.Before(day(2021, time.June, 15))))
+	assert.Equal(t, []string{"v1.1.0"}, fullVersions(versions.Between(day(2021, time.January, 2), day(2022, time.March, 1))))
+
+	since := versions.Since(day(2023, time.January, 1))
+	assert.NotNil(t, since)
+	assert.Empty(t, since)
+}
+
+func TestClient_StrictVersions(t *testing.T) {
+	versions := map[string]string{
+		"somepackage":  "vNext",
+		"pseudo":       "v0.0.0-20190816093944-a6b53ec6cb22",
+		"abbreviated":  "v0.0.0-...-496545a",
+		"incompatible": "v2.0.0+incompatible",
+	}
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/search":
+			if r.URL.Query().Get("page") != "1" {
+				rw.Write([]byte(`<div class="SearchResults"></div>`))
+				return
+			}
+			rw.Write([]byte(`<div class="SearchResults"><div class="SearchSnippet">
+<div class="SearchSnippet-headerContainer"><h2><a href="/github.com/foo/bar">github.com/foo/bar</a></h2></div>
+<div class="SearchSnippet-infoLabel"><span>latest published on Jan 2, 2020</span></div>
+</div></div>`))
+		case r.URL.Query().Get("tab") == "versions":
+			rw.Write([]byte(`<div class="Versions-list">
+  <div class="Version-tag"><a class="js-versionLink">v1.0.0</a></div>
+  <div class="Version-commitTime">Jan 2, 2020</div>
+  <div class="Version-tag"><a class="js-versionLink">1.0</a></div>
+  <div class="Version-commitTime">Jan 1, 2020</div>
+</div>`))
+		default:
+			rw.Write([]byte(fmt.Sprintf(`<div data-test-id="UnitHeader-version"><a>Version: %s</a></div>
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`, versions[strings.Trim(r.URL.Path, "/")])))
+		}
+	}, func(addr string) {
+		t.Run("lenient", func(t *testing.T) {
+			client := New(WithBaseURL("http://" + addr))
+
+			pkg, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+			assert.NoError(t, err)
+			assert.Equal(t, "vNext", pkg.Version)
+			assert.Equal(t, []error{&FieldError{Field: "Version", Raw: "vNext", Err: ErrMalformedVersion{Raw: "vNext"}}}, pkg.Warnings)
+
+			v, err := client.Versions(VersionsRequest{Package: "somepackage"})
+			assert.NoError(t, err)
+			assert.Len(t, v.Versions, 2)
+			assert.Equal(t, []error{&FieldError{Field: "Version", Raw: "1.0", Err: ErrMalformedVersion{Raw: "1.0"}}}, v.Warnings)
+
+			results, err := client.Search(SearchRequest{Query: "bar", Limit: 10})
+			assert.NoError(t, err)
+			assert.Equal(t, "latest", results.Results[0].Version)
+			assert.Equal(t, []error{&FieldError{Field: "Version", Raw: "latest", Err: ErrMalformedVersion{Raw: "latest"}}}, results.Warnings)
+		})
+
+		t.Run("strict", func(t *testing.T) {
+			client := New(WithBaseURL("http://"+addr), WithStrictVersions())
+			var malformed ErrMalformedVersion
+
+			_, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+			assert.ErrorAs(t, err, &malformed)
+			assert.Equal(t, "vNext", malformed.Raw)
+
+			_, err = client.Versions(VersionsRequest{Package: "somepackage"})
+			assert.ErrorAs(t, err, &malformed)
+			assert.Equal(t, "1.0", malformed.Raw)
+
+			_, err = client.Search(SearchRequest{Query: "bar", Limit: 10})
+			assert.ErrorAs(t, err, &malformed)
+			assert.Equal(t, "latest", malformed.Raw)
+
+			for _, pkg := range []string{"pseudo", "abbreviated", "incompatible"} {
+				p, err := client.DescribePackage(DescribePackageRequest{Package: pkg})
+				assert.NoError(t, err, pkg)
+				assert.Equal(t, versions[pkg], p.Version)
+				assert.Empty(t, p.Warnings, pkg)
+			}
+		})
+	})
+}
+
+func TestBuildQuery(t *testing.T) {
+	assert.Equal(t, `author:"Jane Doe" license:MIT yaml parser`, BuildQuery(map[string]string{
+		"license":     "MIT",
+		"author":      "Jane Doe",
+		"description": " ",
+	}, " yaml parser "))
+	assert.Equal(t, "yaml", BuildQuery(nil, "yaml"))
+	assert.Equal(t, `description:"say hi"`, BuildQuery(map[string]string{"Description": `say "hi"`}, ""))
+}
+
+func TestClient_SearchOperators(t *testing.T) {
+	var queries []string
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		rw.Write([]byte(`<div class="SearchResults"></div>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+
+		_, err := client.Search(SearchRequest{Query: BuildQuery(map[string]string{"author": "Jane Doe", "license": "BSD-3-Clause"}, "yaml")})
+		assert.NoError(t, err)
+		_, err = client.Search(SearchRequest{Query: `License:MIT  description:"a b"`})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"q=author%3A%22Jane+Doe%22+license%3ABSD-3-Clause+yaml&page=1",
+			"q=license%3AMIT+description%3A%22a+b%22&page=1",
+		}, queries)
+
+		for _, query := range []string{"owner:someone yaml", "license: yaml", `author:"Jane Doe`} {
+			_, err = client.Search(SearchRequest{Query: query})
+			assert.ErrorIs(t, err, ErrInvalidRequest, query)
+		}
+		assert.Len(t, queries, 2)
+	})
+}
+
+func TestClient_SearchSortBy(t *testing.T) {
+	snippet := func(pkg, importedBy, published string) string {
+		return fmt.Sprintf(`<div class="SearchSnippet">
+  <div class="SearchSnippet-headerContainer"><h2><a href="/%[1]s">%[1]s</a></h2></div>
+  <div class="SearchSnippet-infoLabel">
+    <a href="/%[1]s?tab=importedby"><span>Imported by </span><strong>%[2]s</strong></a>
+    <span><strong>v1.0.0</strong> published on <span data-test-id="snippet-published"><strong>%[3]s</strong></span></span>
+  </div>
+</div>`, pkg, importedBy, published)
+	}
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			rw.Write([]byte(`<div class="SearchResults">` +
+				snippet("example.com/zeta", "5", "Jan 2, 2021") +
+				snippet("example.com/alpha", "10", "sometime") + `</div>`))
+		case "2":
+			rw.Write([]byte(`<div class="SearchResults">` +
+				snippet("example.com/mid", "5", "Mar 4, 2020") +
+				snippet("example.com/beta", "10", "Jan 2, 2021") + `</div>`))
+		default:
+			rw.Write([]byte(`<div class="SearchResults"></div>`))
+		}
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		cases := []struct {
+			sortBy SearchSort
+			expect []string
+		}{
+			{SearchSortRelevance, []string{"example.com/zeta", "example.com/alpha", "example.com/mid", "example.com/beta"}},
+			{SearchSortImportedBy, []string{"example.com/alpha", "example.com/beta", "example.com/zeta", "example.com/mid"}},
+			{SearchSortPublished, []string{"example.com/zeta", "example.com/beta", "example.com/mid", "example.com/alpha"}},
+			{SearchSortName, []string{"example.com/alpha", "example.com/beta", "example.com/mid", "example.com/zeta"}},
+		}
+		for _, tc := range cases {
+			t.Run(tc.sortBy.String(), func(t *testing.T) {
+				results, err := client.Search(SearchRequest{Query: "q", Limit: 10, SortBy: tc.sortBy})
+				assert.NoError(t, err)
+				var got []string
+				for _, result := range results.Results {
+					got = append(got, result.Package)
+				}
+				assert.Equal(t, tc.expect, got)
+			})
+		}
+
+		_, err := client.Search(SearchRequest{Query: "q", SortBy: SearchSort(42)})
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+}
+
+func TestClient_DescribePackageMaintainers(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		head := ""
+		if r.URL.Path == "/example.com/authored" {
+			head = `<meta name="author" content="Jane Doe, John Roe"><meta name="maintainer" content="Jane Doe; ops@example.com">`
+		}
+		rw.Write([]byte(`<html><head>` + head + `</head><body>
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div></body></html>`))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		cases := map[string][]string{
+			"example.com/authored":       {"Jane Doe", "John Roe", "ops@example.com"},
+			"github.com/spf13/cobra/doc": {"spf13"},
+			"git.sr.ht/~sircmpwn/getopt": {"sircmpwn"},
+			"gopkg.in/yaml.v3":           {"go-yaml"},
+			"golang.org/x/mod/semver":    {"golang.org"},
+			"somepackage":                nil,
+		}
+		for pkg, expect := range cases {
+			p, err := client.DescribePackage(DescribePackageRequest{Package: pkg})
+			if assert.NoError(t, err, pkg) {
+				assert.Equal(t, expect, p.Maintainers, pkg)
+			}
+		}
+	})
+}
+
+func TestClient_WithBaseURL(t *testing.T) {
+	t.Run("rejects unusable URLs", func(t *testing.T) {
+		for _, baseURL := range []string{"localhost:8080", "pkg.go.dev", "/pkgsite", "https://", "ftp://example.com", "http://example.com/?q=1"} {
+			client := New(WithBaseURL(baseURL))
+			_, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+			assert.ErrorIs(t, err, ErrInvalidBaseURL, baseURL)
+			_, err = client.Versions(VersionsRequest{Package: "somepackage"})
+			assert.ErrorIs(t, err, ErrInvalidBaseURL, baseURL)
+			_, err = client.ImportedBy(ImportedByRequest{Package: "somepackage"})
+			assert.ErrorIs(t, err, ErrInvalidBaseURL, baseURL)
+			_, err = client.Search(SearchRequest{Query: "q"})
+			assert.ErrorIs(t, err, ErrInvalidBaseURL, baseURL)
+			_, err = client.PackageExists(context.Background(), "somepackage")
+			assert.ErrorIs(t, err, ErrInvalidBaseURL, baseURL)
+			_, err = client.Exists("somepackage")
+			assert.ErrorIs(t, err, ErrInvalidBaseURL, baseURL)
+		}
+	})
+
+	t.Run("keeps the path prefix without doubled slashes", func(t *testing.T) {
+		var mu sync.Mutex
+		var requested []string
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requested = append(requested, r.URL.RequestURI())
+			mu.Unlock()
+			rw.Write([]byte(`<div class="UnitHeader-titleHeading">Heading</div><div>package</div><div class="SearchResults"></div>`))
+		}, func(addr string) {
+			for _, baseURL := range []string{"http://" + addr + "/", "http://" + addr + "/mirror//"} {
+				client := New(WithBaseURL(baseURL))
+				_, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+				assert.NoError(t, err)
+				_, err = client.Versions(VersionsRequest{Package: "somepackage"})
+				assert.NoError(t, err)
+				_, err = client.Search(SearchRequest{Query: "q"})
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, []string{
+				"/somepackage",
+				"/somepackage?tab=versions",
+				"/search?q=q&page=1",
+				"/mirror/somepackage",
+				"/mirror/somepackage?tab=versions",
+				"/mirror/search?q=q&page=1",
+			}, requested)
+		})
+	})
+}
+
+func TestClient_PackagesByStar(t *testing.T) {
+	snippet := func(pkg string) string {
+		return fmt.Sprintf(`<div class="SearchSnippet">
+  <div class="SearchSnippet-headerContainer"><h2><a href="/%[1]s">%[1]s</a></h2></div>
+  <div class="SearchSnippet-infoLabel"><a href="/%[1]s?tab=importedby"><span>Imported by </span><strong>1</strong></a></div>
+</div>`, pkg)
+	}
+	stars := map[string]string{
+		"/a/one":   `<span id="repo-stars-counter-star" title="10">10</span>`,
+		"/b/two":   `<span id="repo-stars-counter-star" title="1,500">1.5k</span>`,
+		"/c/three": `<span id="repo-stars-counter-star">50</span>`,
+	}
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		switch r.Host {
+		case "pkg.go.dev":
+			if r.URL.Query().Get("page") != "1" {
+				rw.Write([]byte(`<div class="SearchResults"></div>`))
+				return
+			}
+			rw.Write([]byte(`<div class="SearchResults">` + snippet("github.com/a/one") + snippet("github.com/b/two") +
+				snippet("example.com/three") + snippet("bitbucket.org/d/four") + `</div>`))
+		case "example.com":
+			rw.Write([]byte(`<html><head><meta name="go-import" content="example.com/three git https://github.com/c/three"></head></html>`))
+		case "github.com":
+			rw.Write([]byte(`<html><body>` + stars[r.URL.Path] + `</body></html>`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}, func(addr string) {
+		transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r = r.Clone(r.Context())
+			r.URL.Scheme, r.URL.Host = "http", addr
+			return http.DefaultTransport.RoundTrip(r)
+		})
+		client := New(WithHTTPClient(&http.Client{Transport: transport}), WithConcurrency(2))
+
+		results, err := client.PackagesByStar(context.Background(), 20, 2000)
+		var errList *ErrorList
+		if assert.ErrorAs(t, err, &errList) {
+			assert.Len(t, errList.Errs, 1)
+			assert.ErrorContains(t, errList.Errs[0], "bitbucket.org/d/four")
+		}
+		if assert.NotNil(t, results) {
+			var got []string
+			for _, result := range results.Results {
+				got = append(got, fmt.Sprintf("%s %d", result.Package, result.Stars))
+			}
+			assert.Equal(t, []string{"github.com/b/two 1500", "example.com/three 50"}, got)
+		}
+
+		_, err = client.PackagesByStar(context.Background(), 10, 5)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+}
+
+func TestClient_SearchIter(t *testing.T) {
+	var mu sync.Mutex
+	var pages []string
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		mu.Lock()
+		pages = append(pages, page)
+		mu.Unlock()
+		if page == "3" {
+			rw.Write([]byte(`<div class="SearchResults"></div>`))
+			return
+		}
+		rw.Write([]byte(fmt.Sprintf(`<div class="SearchResults">
+<div class="SearchSnippet"><div class="SearchSnippet-headerContainer"><h2><a href="/example.com/%[1]s/a">example.com/%[1]s/a</a></h2></div></div>
+<div class="SearchSnippet"><div class="SearchSnippet-headerContainer"><h2><a href="/example.com/%[1]s/b">example.com/%[1]s/b</a></h2></div></div>
+</div>`, page)))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		fetched := func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]string(nil), pages...)
+		}
+		reset := func() {
+			mu.Lock()
+			defer mu.Unlock()
+			pages = nil
+		}
+
+		t.Run("fetches pages as results are consumed", func(t *testing.T) {
+			reset()
+			it := client.SearchIter(SearchRequest{Query: "q", Limit: 10})
+			assert.Empty(t, fetched())
+			var got []string
+			for i := 0; i < 2; i++ {
+				result, err := it.Next()
+				assert.NoError(t, err)
+				got = append(got, result.Package)
+			}
+			assert.Equal(t, []string{"1"}, fetched())
+			for {
+				result, err := it.Next()
+				assert.NoError(t, err)
+				if result == nil {
+					break
+				}
+				got = append(got, result.Package)
+			}
+			assert.NoError(t, it.Err())
+			assert.Equal(t, []string{"example.com/1/a", "example.com/1/b", "example.com/2/a", "example.com/2/b"}, got)
+			assert.Equal(t, []string{"1", "2", "3"}, fetched())
+		})
+
+		t.Run("stops at the limit", func(t *testing.T) {
+			reset()
+			it := client.SearchIter(SearchRequest{Query: "q", Limit: 3})
+			count := 0
+			for result, err := it.Next(); result != nil; result, err = it.Next() {
+				assert.NoError(t, err)
+				count++
+			}
+			assert.Equal(t, 3, count)
+			assert.Equal(t, []string{"1", "2"}, fetched())
+		})
+
+		t.Run("honors cancellation between pages", func(t *testing.T) {
+			reset()
+			ctx, cancel := context.WithCancel(context.Background())
+			it := client.SearchIter(SearchRequest{Query: "q", Limit: 10, Context: ctx})
+			for i := 0; i < 2; i++ {
+				_, err := it.Next()
+				assert.NoError(t, err)
+			}
+			cancel()
+			result, err := it.Next()
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.ErrorIs(t, it.Err(), context.Canceled)
+			assert.Equal(t, []string{"1"}, fetched())
+
+			_, err = client.Search(SearchRequest{Query: "q", Limit: 10, Context: ctx})
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+
+		t.Run("refuses invalid requests", func(t *testing.T) {
+			for _, req := range []SearchRequest{
+				{Query: "q", SortBy: SearchSortName},
+				{Query: "q", Page: -1},
+			} {
+				_, err := client.SearchIter(req).Next()
+				assert.ErrorIs(t, err, ErrInvalidRequest)
+			}
+		})
+	})
+}
+
+func TestClient_CacheStats(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		rw.Write([]byte(`<div data-test-id="UnitHeader-version"><a>Version: v1.0.0</a></div>
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div>`))
+	}, func(addr string) {
+		hits, misses, entries := New().CacheStats()
+		assert.Equal(t, [3]int{0, 0, 0}, [3]int{hits, misses, entries}, "no cache")
+		New().CacheEvict("http://" + addr + "/somepackage")
+
+		client := New(WithBaseURL("http://"+addr), WithCache(time.Minute))
+		describe := func(pkg string) {
+			_, err := client.DescribePackage(DescribePackageRequest{Package: pkg})
+			assert.NoError(t, err)
+		}
+		describe("somepackage")
+		describe("somepackage")
+		describe("otherpackage")
+		hits, misses, entries = client.CacheStats()
+		assert.Equal(t, [3]int{1, 2, 2}, [3]int{hits, misses, entries})
+
+		client.CacheEvict("http://" + addr + "/somepackage")
+		_, _, entries = client.CacheStats()
+		assert.Equal(t, 1, entries)
+		describe("somepackage")
+		describe("otherpackage")
+		assert.Equal(t, 3, requests, "only the evicted entry is fetched again")
+		hits, misses, entries = client.CacheStats()
+		assert.Equal(t, [3]int{2, 3, 2}, [3]int{hits, misses, entries})
+	})
+}
+
+func TestClient_PageIterators(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/missing":
+			rw.WriteHeader(http.StatusNotFound)
+		case r.URL.Query().Get("tab") == "importedby":
+			rw.Write([]byte(`<html><body>
+<div class="u-breakWord">example.com/a</div>
+<div class="u-breakWord">example.com/b</div>
+<div class="u-breakWord">example.com/c</div>
+</body></html>`))
+		case r.URL.Query().Get("tab") == "versions":
+			rw.Write([]byte(`<html><body>
+<div class="UnitMeta-repo"><a>github.com/foo/bar</a></div>
+<div class="Versions-list">
+  <div class="Version-tag"><a class="js-versionLink">v1.2.0</a></div>
+  <div class="Version-commitTime">Mar 1, 2023</div>
+  <div class="Version-tag"><a class="js-versionLink">v1.1.0</a></div>
+  <div class="Version-commitTime">Jun 1, 2021</div>
+  <div class="Version-tag"><a class="js-versionLink">v1.0.0</a></div>
+  <div class="Version-commitTime">Jan 1, 2020</div>
+</div></body></html>`))
+		}
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+
+		t.Run("ImportedByIter", func(t *testing.T) {
+			it := client.ImportedByIter(ImportedByRequest{Package: "somepackage"})
+			var got []string
+			for importer, err := it.Next(); importer != nil; importer, err = it.Next() {
+				assert.NoError(t, err)
+				got = append(got, importer.Package)
+			}
+			assert.NoError(t, it.Err())
+			assert.Equal(t, []string{"example.com/a", "example.com/b", "example.com/c"}, got)
+
+			// the iterator is single-use
+			importer, err := it.Next()
+			assert.Nil(t, importer)
+			assert.NoError(t, err)
+			it.Close()
+		})
+
+		t.Run("stopping early", func(t *testing.T) {
+			it := client.ImportedByIter(ImportedByRequest{Package: "somepackage"})
+			importer, err := it.Next()
+			assert.NoError(t, err)
+			assert.Equal(t, &Importer{Package: "example.com/a", Module: "example.com/a"}, importer)
+			it.Close()
+			importer, err = it.Next()
+			assert.Nil(t, importer)
+			assert.NoError(t, err)
+			it.Close()
+
+			client.ImportedByIter(ImportedByRequest{Package: "somepackage"}).Close()
+		})
+
+		t.Run("VersionsIter", func(t *testing.T) {
+			cutoff := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+			it := client.VersionsIter(VersionsRequest{Package: "github.com/foo/bar"})
+			defer it.Close()
+			var older *Version
+			for v, err := it.Next(); v != nil; v, err = it.Next() {
+				assert.NoError(t, err)
+				if published, err := v.PublishedTime(); err == nil && published.Before(cutoff) {
+					older = v
+					break
+				}
+			}
+			if assert.NotNil(t, older) {
+				assert.Equal(t, "v1.1.0", older.FullVersion)
+				assert.Equal(t, "github.com/foo/bar", older.Repository)
+			}
+		})
+
+		t.Run("errors", func(t *testing.T) {
+			v, err := client.VersionsIter(VersionsRequest{Package: "missing"}).Next()
+			assert.Nil(t, v)
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			it := client.ImportedByIter(ImportedByRequest{Package: "missing"})
+			importer, err := it.Next()
+			assert.Nil(t, importer)
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.ErrorIs(t, it.Err(), ErrNotFound)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err = client.VersionsIter(VersionsRequest{Package: "somepackage", Context: ctx}).Next()
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+	})
+}
+
+func TestDiffPackages(t *testing.T) {
+	old := &Package{
+		Package:         "example.com/mod",
+		Version:         "v1.0.0",
+		Published:       "2024-01-02",
+		License:         "MIT",
+		MajorVersions:   []string{"example.com/mod"},
+		ImportedByCount: 100,
+		Stars:           10,
+	}
+
+	t.Run("no changes", func(t *testing.T) {
+		same := *old
+		same.MajorVersions = []string{"example.com/mod"}
+		// the relative date of an unchanged version drifted
+		same.Published = "2024-01-03"
+		diff := DiffPackages(old, &same)
+		assert.Empty(t, diff.Changes)
+		assert.False(t, diff.HasMaterialChanges())
+	})
+
+	t.Run("informational changes", func(t *testing.T) {
+		changed := *old
+		changed.ImportedByCount = 105
+		changed.Stars = 12
+		diff := DiffPackages(old, &changed)
+		assert.Equal(t, []FieldChange{
+			{Field: DiffImportedByCount, Old: 100, New: 105},
+			{Field: DiffStars, Old: 10, New: 12},
+		}, diff.Changes)
+		assert.False(t, diff.HasMaterialChanges())
+	})
+
+	t.Run("material changes", func(t *testing.T) {
+		changed := *old
+		changed.Version = "v1.1.0"
+		changed.Published = "2024-03-04"
+		changed.License = "Apache-2.0"
+		changed.ImportedByCount = 150
+		changed.Deprecated = true
+		diff := DiffPackages(old, &changed)
+		assert.Equal(t, "example.com/mod", diff.Package)
+		assert.Equal(t, []FieldChange{
+			{Field: DiffVersion, Old: "v1.0.0", New: "v1.1.0", Material: true},
+			{Field: DiffPublished, Old: "2024-01-02", New: "2024-03-04"},
+			{Field: DiffLicense, Old: "MIT", New: "Apache-2.0", Material: true},
+			{Field: DiffImportedByCount, Old: 100, New: 150, Material: true},
+			{Field: DiffDeprecated, Old: false, New: true, Material: true},
+		}, diff.Changes)
+		assert.True(t, diff.HasMaterialChanges())
+
+		data, err := json.Marshal(PackageDiff{Package: diff.Package, Changes: diff.Changes[3:]})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"package": "example.com/mod", "changes": [
+			{"field": "ImportedByCount", "old": 100, "new": 150, "material": true},
+			{"field": "Deprecated", "old": false, "new": true, "material": true}
+		]}`, string(data))
+	})
+
+	t.Run("nil old", func(t *testing.T) {
+		diff := DiffPackages(nil, &Package{Package: "example.com/mod", Version: "v1.0.0"})
+		assert.Equal(t, []FieldChange{{Field: DiffVersion, Old: "", New: "v1.0.0", Material: true}}, diff.Changes)
+	})
+}
+
+func TestClient_DescribePackageHeaderCounts(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<html><body><div class="UnitHeader">
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div>
+<span class="go-Chip">deprecated</span>
+<a data-test-id="UnitHeader-importedby">Imported by: 1,234</a>
+</div></body></html>`))
+	}, func(addr string) {
+		pkg, err := New(WithBaseURL("http://" + addr)).DescribePackage(DescribePackageRequest{Package: "somepackage"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1234, pkg.ImportedByCount)
+		assert.True(t, pkg.Deprecated)
+	})
+}
+
+func TestClient_SearchCursor(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.RawQuery)
+		mu.Unlock()
+		page := r.URL.Query().Get("page")
+		if page == "4" {
+			rw.Write([]byte(`<div class="SearchResults"></div>`))
+			return
+		}
+		next := ""
+		if page == "1" {
+			// pkg.go.dev's own link, with a parameter of its own
+			next = `<a class="Pagination-next" href="/search?m=package&amp;page=2&amp;q=q">Next</a>`
+		}
+		rw.Write([]byte(fmt.Sprintf(`<div class="SearchResults">
+<div class="SearchSnippet"><div class="SearchSnippet-headerContainer"><h2><a href="/example.com/%[1]s">example.com/%[1]s</a></h2></div></div>
+</div>%[2]s`, page, next)))
+	}, func(addr string) {
+		client := New(WithBaseURL("http://" + addr))
+		packages := func(results *SearchResults) []string {
+			var got []string
+			for _, result := range results.Results {
+				got = append(got, result.Package)
+			}
+			return got
+		}
+
+		first, err := client.Search(SearchRequest{Query: "q", Limit: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"example.com/1"}, packages(first))
+		assert.NotEmpty(t, first.NextCursor)
+
+		second, err := client.Search(SearchRequest{Query: "q", Limit: 1, Cursor: first.NextCursor})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"example.com/2"}, packages(second))
+
+		// without a link, the page number is counted up
+		rest, err := client.Search(SearchRequest{Limit: 10, Cursor: second.NextCursor})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"example.com/3"}, packages(rest))
+		assert.Empty(t, rest.NextCursor)
+
+		assert.Equal(t, []string{"q=q&page=1", "m=package&page=2&q=q", "m=package&page=3&q=q", "m=package&page=4&q=q"}, requested)
+
+		for _, req := range []SearchRequest{
+			{Query: "q", Cursor: "not base64!"},
+			{Query: "other", Cursor: first.NextCursor},
+			{Query: "q", Cursor: first.NextCursor, Page: 2},
+		} {
+			_, err = client.Search(req)
+			assert.ErrorIs(t, err, ErrInvalidRequest, req.Cursor)
+		}
+	})
+}
+
+func TestSnapshots(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		pkg := &Package{
+			Package:         "github.com/foo/bar",
+			Version:         "v1.2.0",
+			MajorVersions:   []string{"v1", "v2"},
+			ImportedByCount: 42,
+			CoveragePercent: 87.5,
+			hasCoverage:     true,
+			Warnings:        []error{&FieldError{Field: "Published", Raw: "soon", Err: ErrUnexpectedContent}},
+		}
+		versions := &Versions{
+			Package:  "github.com/foo/bar",
+			Versions: []Version{{MajorVersion: "v1", FullVersion: "v1.2.0", Date: "Jan 2, 2024"}},
+			Warnings: []error{errors.New("odd date")},
+		}
+		importedBy := &ImportedBy{
+			Package:    "github.com/foo/bar",
+			ImportedBy: []string{"github.com/baz/qux"},
+			Groups:     []ImporterGroup{{Module: "github.com/baz/qux", Packages: []string{"github.com/baz/qux"}}},
+		}
+
+		for _, v := range []any{pkg, versions, importedBy} {
+			var buf bytes.Buffer
+			if !assert.NoError(t, SaveSnapshot(&buf, v)) {
+				continue
+			}
+			loaded, err := LoadSnapshot(&buf)
+			if !assert.NoError(t, err) {
+				continue
+			}
+			switch loaded := loaded.(type) {
+			case *Package:
+				assert.Equal(t, pkg.MajorVersions, loaded.MajorVersions)
+				assert.Equal(t, 42, loaded.ImportedByCount)
+				assert.True(t, loaded.HasCoverageData())
+				if assert.Len(t, loaded.Warnings, 1) {
+					assert.Equal(t, pkg.Warnings[0].Error(), loaded.Warnings[0].Error())
+				}
+			case *Versions:
+				assert.Equal(t, versions.Versions, loaded.Versions)
+				if assert.Len(t, loaded.Warnings, 1) {
+					assert.Equal(t, "odd date", loaded.Warnings[0].Error())
+				}
+			case *ImportedBy:
+				assert.Equal(t, importedBy, loaded)
+			default:
+				t.Errorf("loaded %T from a snapshot of %T", loaded, v)
+			}
+		}
+	})
+
+	t.Run("compatibility", func(t *testing.T) {
+		// a snapshot from a later release, with fields this one doesn't know
+		loaded, err := LoadSnapshot(strings.NewReader(`{"schema":1,"type":"Package","savedAt":"2026-01-02T00:00:00Z","origin":"cron",
+			"data":{"Package":"github.com/foo/bar","Version":"v1.0.0","Funding":{"url":"https://example.com"}}}`))
+		if assert.NoError(t, err) {
+			pkg := loaded.(*Package)
+			assert.Equal(t, "v1.0.0", pkg.Version)
+			// and without those added since, which load as zero values
+			assert.Zero(t, pkg.ImportedByCount)
+			assert.Nil(t, pkg.Maintainers)
+			assert.Nil(t, pkg.Warnings)
+		}
+
+		_, err = LoadSnapshot(strings.NewReader(`{"schema":2,"type":"Package","data":{}}`))
+		assert.ErrorIs(t, err, ErrUnsupportedSnapshot)
+		_, err = LoadSnapshot(strings.NewReader(`{"type":"Package","data":{}}`))
+		assert.ErrorIs(t, err, ErrUnsupportedSnapshot)
+		_, err = LoadSnapshot(strings.NewReader(`{"schema":1,"type":"Symbol","data":{}}`))
+		assert.ErrorIs(t, err, ErrUnsupportedSnapshot)
+	})
+
+	t.Run("unsupported values", func(t *testing.T) {
+		assert.ErrorIs(t, SaveSnapshot(io.Discard, Package{}), ErrUnsupportedSnapshot)
+		assert.ErrorIs(t, SaveSnapshot(io.Discard, (*Versions)(nil)), ErrUnsupportedSnapshot)
+	})
+}
+
+func TestClient_SprinkleNetworkCounts(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		switch r.Host {
+		case "github.com":
+			rw.Write([]byte(`<html><body><p class="f4 my-3">Some description</p>
+<span id="repo-network-counter" title="1,234">1.2k</span>
+<a href="/foo/bar/watchers"><strong>56</strong> watching</a></body></html>`))
+		default:
+			rw.Write([]byte(`<html><body><div class="home-panel-description-markdown"><p>Some description</p></div>
+<span id="repo-network-counter">7</span></body></html>`))
+		}
+	}, func(addr string) {
+		transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r = r.Clone(r.Context())
+			r.URL.Scheme, r.URL.Host = "http", addr
+			return http.DefaultTransport.RoundTrip(r)
+		})
+		client := New(WithHTTPClient(&http.Client{Transport: transport}))
+
+		p := &Package{Package: "github.com/foo/bar", Repository: "https://github.com/foo/bar"}
+		assert.NoError(t, client.Sprinkle(p))
+		assert.Equal(t, 1234, p.ForkCount)
+		assert.Equal(t, 56, p.WatcherCount)
+
+		p = &Package{Package: "github.com/foo/bar", Repository: "https://github.com/foo/bar"}
+		assert.NoError(t, client.Sprinkle(p, SprinkleNoNetworkCounts))
+		assert.Zero(t, p.ForkCount)
+		assert.Zero(t, p.WatcherCount)
+
+		// other hosts leave them at zero
+		p = &Package{Package: "gitlab.com/foo/bar", Repository: "https://gitlab.com/foo/bar"}
+		assert.NoError(t, client.Sprinkle(p))
+		assert.Zero(t, p.ForkCount)
+		assert.Zero(t, p.WatcherCount)
+	})
+}
+
+func TestClient_DescribePackageSupersededBy(t *testing.T) {
+	cases := map[string]string{
+		// moved module
+		`<div class="go-Message go-Message--notice">The module github.com/dgrijalva/jwt-go is now available as
+<a href="/github.com/golang-jwt/jwt@v3.2.2+incompatible">github.com/golang-jwt/jwt</a>.</div>`: "github.com/golang-jwt/jwt",
+		// go.mod deprecation message, with a link to the docs on modules
+		`<div class="go-Message go-Message--alert">Deprecated: Use github.com/golang-jwt/jwt instead.
+<a href="https://go.dev/ref/mod#go-mod-file-module-deprecation">Learn more</a></div>`: "github.com/golang-jwt/jwt",
+		// deprecated without a successor
+		`<div class="go-Message go-Message--alert">Deprecated: this module is unmaintained.</div>`: "",
+		// not a migration notice
+		`<div class="go-Message go-Message--notice">The highest tagged major version is
+<a href="/github.com/dgrijalva/jwt-go/v4">v4</a>.</div>`: "",
+	}
+	for banner, expect := range cases {
+		withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+			rw.Write([]byte(`<html><body><div class="UnitHeader">
+<div class="UnitHeader-titleHeading">Heading</div><div>package</div>
+</div>` + banner + `</body></html>`))
+		}, func(addr string) {
+			pkg, err := New(WithBaseURL("http://" + addr)).DescribePackage(DescribePackageRequest{Package: "github.com/dgrijalva/jwt-go"})
+			assert.NoError(t, err)
+			assert.Equal(t, expect, pkg.SupersededBy, banner)
+			assert.False(t, pkg.Deprecated, banner)
+		})
+	}
+}
+
+func TestClient_FetchGoSum(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/lookup/github.com/!burnt!sushi/toml@v1.3.2":
+			if n == 1 {
+				rw.Header().Set("Retry-After", "0")
+				rw.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			fmt.Fprint(rw, `21011
+github.com/BurntSushi/toml v1.3.2 h1:o7IhLm0Msx3BaB+n3Ag7L8EVlByGnpq14C4YWiu/gL8=
+github.com/BurntSushi/toml v1.3.2/go.mod h1:CxXYINrC8qIiEnFrOxCa7Jy5BFHlXnUU2pbicEuybxQ=
+
+go.sum database tree
+21345
+hash
+
+— sum.golang.org signature
+`)
+		case "/lookup/github.com/foo/limited@v1.0.0":
+			rw.WriteHeader(http.StatusTooManyRequests)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}, func(addr string) {
+		client := New(WithSumDBURL("http://"+addr), WithCache(time.Minute))
+		ctx := context.Background()
+
+		sum, err := client.FetchGoSum(ctx, "github.com/BurntSushi/toml", "v1.3.2")
+		assert.NoError(t, err)
+		assert.Equal(t, "github.com/BurntSushi/toml v1.3.2 h1:o7IhLm0Msx3BaB+n3Ag7L8EVlByGnpq14C4YWiu/gL8=\n"+
+			"github.com/BurntSushi/toml v1.3.2/go.mod h1:CxXYINrC8qIiEnFrOxCa7Jy5BFHlXnUU2pbicEuybxQ=\n", sum)
+		assert.Equal(t, 2, requests)
+
+		// cached with the other responses
+		again, err := client.FetchGoSum(ctx, "github.com/BurntSushi/toml", "v1.3.2")
+		assert.NoError(t, err)
+		assert.Equal(t, sum, again)
+		assert.Equal(t, 2, requests)
+		hits, _, entries := client.CacheStats()
+		assert.Equal(t, 1, hits)
+		assert.Equal(t, 1, entries)
+
+		_, err = client.FetchGoSum(ctx, "github.com/foo/bar", "v1.0.0")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		_, err = client.FetchGoSum(ctx, "github.com/foo/bar", "latest")
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+
+		// the wait is capped, so a canceled context stops the retries
+		canceled, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err = client.FetchGoSum(canceled, "github.com/foo/limited", "v1.0.0")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		_, err = New(WithPkgGoDevOnly()).FetchGoSum(ctx, "github.com/foo/bar", "v1.0.0")
+		assert.ErrorIs(t, err, ErrExternalFetchDisabled)
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, 5*time.Second, retryAfter("5"))
+	assert.Equal(t, defaultSumDBBackoff, retryAfter(""))
+	assert.Equal(t, defaultSumDBBackoff, retryAfter("soon"))
+	assert.Equal(t, maxSumDBBackoff, retryAfter("3600"))
+	assert.Equal(t, time.Duration(0), retryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)))
+}
+
+func TestClient_WithTimezone(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`<html><body><div class="UnitHeader-titleHeading">Heading</div><div>package</div>
+<span data-test-id="UnitHeader-commitTime">Published: 1 hour ago</span></body></html>`))
+	}, func(addr string) {
+		// 9pm in New York is already the next day in UTC
+		newYork := time.FixedZone("EST", -5*60*60)
+		now := time.Date(2024, time.March, 1, 21, 0, 0, 0, newYork)
+
+		for _, c := range []struct {
+			loc    *time.Location
+			expect string
+		}{
+			{nil, "2024-03-02"},
+			{time.UTC, "2024-03-02"},
+			{newYork, "2024-03-01"},
+		} {
+			var opts []func(c *client)
+			if c.loc != nil {
+				opts = append(opts, WithTimezone(c.loc))
+			}
+			client := New(append(opts, WithBaseURL("http://"+addr))...)
+			client.clock = func() time.Time { return now }
+			pkg, err := client.DescribePackage(DescribePackageRequest{Package: "somepackage"})
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expect, pkg.Published, c.loc)
+				expectLoc := c.loc
+				if expectLoc == nil {
+					expectLoc = time.UTC
+				}
+				assert.Equal(t, expectLoc, pkg.Response.FetchedAt.Location(), c.loc)
+			}
+		}
+	})
+}
+
+func TestVersions_MajorLines(t *testing.T) {
+	versions := &Versions{Versions: []Version{
+		{MajorVersion: "v10", FullVersion: "v10.0.1"},
+		{MajorVersion: "v9", FullVersion: "v9.3.0"},
+		{MajorVersion: "v2", FullVersion: "v2.1.0"},
+		{MajorVersion: "v2", FullVersion: "v2.0.0"},
+		{FullVersion: "v1.4.0"},
+		{MajorVersion: "v0", FullVersion: "v0.9.0"},
+	}}
+	assert.Equal(t, []string{"v0", "v1", "v2", "v9", "v10"}, versions.MajorLines())
+	assert.Equal(t, []string{}, (&Versions{}).MajorLines())
+}
+
+func TestClient_DownloadImagesOptions(t *testing.T) {
+	withHTTPServer("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "image/png")
+		rw.Write([]byte("png"))
+	}, func(addr string) {
+		images := ImageSet{
+			{Alt: "allowed", URL: "http://" + addr + "/allowed.png"},
+			{Alt: "elsewhere", URL: "http://elsewhere.invalid/tracker.png"},
+		}
+		host, _, _ := net.SplitHostPort(addr)
+
+		dir := t.TempDir()
+		saved, err := New(WithAllowedHosts(host)).DownloadImages(context.Background(), images, dir)
+		var errs *ErrorList
+		if assert.ErrorAs(t, err, &errs) && assert.Len(t, errs.Errs, 1) {
+			var notAllowed ErrHostNotAllowed
+			assert.ErrorAs(t, errs.Errs[0], &notAllowed)
+			assert.Equal(t, "elsewhere.invalid", notAllowed.Host)
+		}
+		assert.Equal(t, map[string]string{"allowed": filepath.Join(dir, "allowed.png")}, saved)
+
+		saved, err = New(WithBaseURL("http://"+addr), WithPkgGoDevOnly()).DownloadImages(context.Background(), images, t.TempDir())
+		if assert.ErrorAs(t, err, &errs) && assert.Len(t, errs.Errs, 1) {
+			assert.ErrorIs(t, errs.Errs[0], ErrExternalFetchDisabled)
+		}
+		assert.Len(t, saved, 1)
+	})
+}
@@ -0,0 +1,145 @@
+package pkggodev
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// PackageComparison is the outcome of comparing two packages metric by metric
+type PackageComparison struct {
+	A, B           *Package
+	AStats, BStats *PackageStats // nil when the counts couldn't be fetched
+	AWins, BWins   int
+	Ties           int
+	Metrics        []MetricOutcome // in the order they were compared
+}
+
+// MetricOutcome is how two packages compared on one metric
+type MetricOutcome struct {
+	Metric string
+	Winner string // path of the winning package, empty on a tie
+}
+
+// comparedMetric compares a metric of two packages, returning a positive
+// number when a wins, a negative one when b wins and zero on a tie
+type comparedMetric[T any] struct {
+	name    string
+	compare func(a, b *T) int
+}
+
+// boolRank orders true above false
+func boolRank(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// packageMetrics are the metrics compared from the unit pages
+var packageMetrics = []comparedMetric[Package]{
+	{"published", func(a, b *Package) int {
+		// normalized dates sort as strings, an unknown one loses
+		return cmp.Compare(a.Published, b.Published)
+	}},
+	{"stable version", func(a, b *Package) int {
+		return cmp.Compare(boolRank(a.HasStableVersion), boolRank(b.HasStableVersion))
+	}},
+	{"valid go.mod", func(a, b *Package) int {
+		return cmp.Compare(boolRank(a.HasValidGoModFile), boolRank(b.HasValidGoModFile))
+	}},
+	{"redistributable license", func(a, b *Package) int {
+		return cmp.Compare(boolRank(a.HasRedistributableLicense), boolRank(b.HasRedistributableLicense))
+	}},
+	{"documented symbols", func(a, b *Package) int {
+		return cmp.Compare(a.DocumentedSymbolCount, b.DocumentedSymbolCount)
+	}},
+}
+
+// statsMetrics are the metrics compared from Stats
+var statsMetrics = []comparedMetric[PackageStats]{
+	{"imported by", func(a, b *PackageStats) int {
+		return cmp.Compare(a.ImportedByCount, b.ImportedByCount)
+	}},
+	{"versions", func(a, b *PackageStats) int {
+		return cmp.Compare(a.VersionCount, b.VersionCount)
+	}},
+	{"dependencies", func(a, b *PackageStats) int {
+		// fewer dependencies win
+		return cmp.Compare(b.DirectDepsCount, a.DirectDepsCount)
+	}},
+}
+
+// ComparePackages describes packages a and b and gathers their Stats, all
+// concurrently and fetching each unit page once, then compares them on each
+// metric: publication date, stable version, valid go.mod, redistributable
+// license, documented symbols, importers, versions and, where fewer is
+// better, direct dependencies. An error is returned when either package
+// can't be described. When the counts of Stats are incomplete for either
+// package, the metrics based on them are skipped and the failures returned in
+// an ErrorList alongside the comparison.
+func (c *client) ComparePackages(ctx context.Context, a, b string) (*PackageComparison, error) {
+	paths := [2]string{a, b}
+	var pkgs [2]*Package
+	var stats [2]*PackageStats
+	var describeErrs, statsErrs [2]error
+
+	var wg sync.WaitGroup
+	for i, pkg := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// the counts of the unit page are scraped while describing it
+			stats[i], statsErrs[i] = c.gatherStats(ctx, pkg, func(unitStats *PackageStats) error {
+				pkgs[i], describeErrs[i] = c.describePackage(DescribePackageRequest{Package: pkg}, func(col *colly.Collector) {
+					col.Context = ctx
+					trackUnitStats(col, c.selectors, unitStats)
+				})
+				return describeErrs[i]
+			})
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range describeErrs {
+		if err != nil {
+			return nil, fmt.Errorf("describing '%s': %w", paths[i], err)
+		}
+	}
+
+	comparison := &PackageComparison{A: pkgs[0], B: pkgs[1], AStats: stats[0], BStats: stats[1]}
+	record := func(metric string, result int) {
+		outcome := MetricOutcome{Metric: metric}
+		switch {
+		case result > 0:
+			outcome.Winner = a
+			comparison.AWins++
+		case result < 0:
+			outcome.Winner = b
+			comparison.BWins++
+		default:
+			comparison.Ties++
+		}
+		comparison.Metrics = append(comparison.Metrics, outcome)
+	}
+	for _, metric := range packageMetrics {
+		record(metric.name, metric.compare(pkgs[0], pkgs[1]))
+	}
+
+	errs := &ErrorList{}
+	for i, err := range statsErrs {
+		if err != nil {
+			errs.Errs = append(errs.Errs, fmt.Errorf("gathering stats of '%s': %w", paths[i], err))
+		}
+	}
+	if len(errs.Errs) > 0 {
+		return comparison, errs
+	}
+	for _, metric := range statsMetrics {
+		record(metric.name, metric.compare(stats[0], stats[1]))
+	}
+	return comparison, nil
+}
@@ -0,0 +1,116 @@
+package pkggodev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// defaultMaxGraphNodes is used unless changed with WithMaxGraphNodes
+const defaultMaxGraphNodes = 1000
+
+// ErrGraphTruncated is returned alongside a partial graph when the node cap
+// set with WithMaxGraphNodes was reached
+var ErrGraphTruncated = errors.New("graph truncated at the maximum number of nodes")
+
+// WithMaxGraphNodes caps how many packages ResolveDependents discovers, since
+// popular packages have importers by the thousand. Values below 1 are ignored.
+func WithMaxGraphNodes(n int) func(c *client) {
+	return func(c *client) {
+		if n > 0 {
+			c.maxGraphNodes = n
+		}
+	}
+}
+
+// ResolveDependents builds the reverse dependency graph of pkg, mapping each
+// package to its importers, following importers up to depth levels away.
+// Packages at the last level are listed as importers but not resolved. When
+// some lookups fail or the node cap is reached, the partial graph is returned
+// along with an ErrorList holding the failures and ErrGraphTruncated.
+func (c *client) ResolveDependents(ctx context.Context, pkg string, depth int) (map[string][]string, error) {
+	if depth < 1 {
+		return nil, fmt.Errorf("%w: depth must be positive, got %d", ErrInvalidRequest, depth)
+	}
+
+	graph := map[string][]string{}
+	visited := map[string]bool{pkg: true}
+	errs := &ErrorList{}
+	frontier := []string{pkg}
+
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		importers, err := c.importersOf(ctx, frontier, errs)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []string
+		truncated := false
+		for i, p := range frontier {
+			if importers[i] == nil {
+				continue
+			}
+			graph[p] = importers[i]
+			for _, importer := range importers[i] {
+				if visited[importer] {
+					continue
+				}
+				if len(visited) >= c.maxGraphNodes {
+					truncated = true
+					break
+				}
+				visited[importer] = true
+				next = append(next, importer)
+			}
+		}
+		if truncated {
+			errs.Errs = append(errs.Errs, ErrGraphTruncated)
+			break
+		}
+		frontier = next
+	}
+
+	if len(errs.Errs) > 0 {
+		return graph, errs
+	}
+	return graph, nil
+}
+
+// importersOf looks up the importers of pkgs concurrently. Failed lookups are
+// added to errs and leave a nil entry, an error is only returned when ctx is
+// done.
+func (c *client) importersOf(ctx context.Context, pkgs []string, errs *ErrorList) ([][]string, error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	importers := make([][]string, len(pkgs))
+	sem := make(chan struct{}, defaultBatchConcurrency)
+
+	for i, pkg := range pkgs {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			importedBy, err := c.ImportedBy(ImportedByRequest{Package: pkg, Context: ctx})
+			if err != nil {
+				mu.Lock()
+				errs.Errs = append(errs.Errs, fmt.Errorf("looking up importers of '%s': %w", pkg, err))
+				mu.Unlock()
+				return
+			}
+			importers[i] = importedBy.ImportedBy
+			if importers[i] == nil {
+				importers[i] = []string{}
+			}
+		}()
+	}
+	wg.Wait()
+	return importers, nil
+}
@@ -0,0 +1,112 @@
+package pkggodev
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+// DocIndex is the table of contents of a package's documentation
+type DocIndex struct {
+	Package string
+	// Sections are "Constants", "Variables", "Functions", "Types" and
+	// "Examples", in that order, leaving out the ones the package doesn't have
+	Sections []DocIndexNode
+	// Truncated is set when the documentation was collapsed or cut short on
+	// pkg.go.dev, so the index may be incomplete
+	Truncated bool
+}
+
+// DocIndexNode is an entry of the documentation index. Types hold their
+// constructors and methods as children, sections hold their entries.
+type DocIndexNode struct {
+	Kind string // "section", "function", "type", "method" or "example"
+	// Title is the entry as shown, like "func (l *List[T]) Push(v T)"
+	Title string
+	// Anchor is the fragment of the entry on the package's page. For
+	// symbols it's the name Symbol looks up, like "List.Push".
+	Anchor   string
+	Children []DocIndexNode
+}
+
+// newDocIndexNode reads the node of an index entry from its link
+func newDocIndexNode(kind string, link *goquery.Selection) DocIndexNode {
+	return DocIndexNode{
+		Kind:   kind,
+		Title:  strings.Join(strings.Fields(link.Text()), " "),
+		Anchor: strings.TrimPrefix(link.AttrOr("href", ""), "#"),
+	}
+}
+
+// DocIndex scrapes the documentation index of pkg, keeping the nesting of
+// methods and constructors below their type
+func (c *client) DocIndex(ctx context.Context, pkg string) (*DocIndex, error) {
+	if c.baseURLErr != nil {
+		return nil, c.baseURLErr
+	}
+	col := c.newCollector()
+	col.Context = ctx
+	sel := c.selectors
+	index := &DocIndex{Package: pkg}
+	var err error
+
+	onTruncation(col, sel, func(section string) {
+		index.Truncated = index.Truncated || section == "documentation"
+	})
+	col.OnHTML(sel.DocIndexList, func(e *colly.HTMLElement) {
+		var consts, vars, funcs, types []DocIndexNode
+		e.DOM.ChildrenFiltered("li").Each(func(_ int, item *goquery.Selection) {
+			link := item.ChildrenFiltered("a[href]").First()
+			switch {
+			case item.Is(sel.DocIndexConstants):
+				consts = append(consts, newDocIndexNode("section", link))
+			case item.Is(sel.DocIndexVariables):
+				vars = append(vars, newDocIndexNode("section", link))
+			case item.Is(sel.DocIndexType):
+				typ := newDocIndexNode("type", link)
+				item.ChildrenFiltered("ul").ChildrenFiltered("li").Each(func(_ int, member *goquery.Selection) {
+					kind := "function"
+					if member.Is(sel.DocIndexMethod) {
+						kind = "method"
+					}
+					typ.Children = append(typ.Children, newDocIndexNode(kind, member.ChildrenFiltered("a[href]").First()))
+				})
+				types = append(types, typ)
+			case item.Is(sel.DocIndexFunction):
+				funcs = append(funcs, newDocIndexNode("function", link))
+			}
+		})
+
+		// constants and variables are listed as a single link to their group
+		index.Sections = append(index.Sections, consts...)
+		index.Sections = append(index.Sections, vars...)
+		if len(funcs) > 0 {
+			index.Sections = append(index.Sections, DocIndexNode{Kind: "section", Title: "Functions", Anchor: "pkg-functions", Children: funcs})
+		}
+		if len(types) > 0 {
+			index.Sections = append(index.Sections, DocIndexNode{Kind: "section", Title: "Types", Anchor: "pkg-types", Children: types})
+		}
+	})
+	var examples []DocIndexNode
+	col.OnHTML(sel.DocExample, func(e *colly.HTMLElement) {
+		examples = append(examples, newDocIndexNode("example", e.DOM))
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		if r.StatusCode == 404 {
+			err = ErrNotFound
+			return
+		}
+		err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
+	})
+	col.Visit(c.pageURL(pkg))
+	if err != nil {
+		return nil, err
+	}
+	if len(examples) > 0 {
+		index.Sections = append(index.Sections, DocIndexNode{Kind: "section", Title: "Examples", Anchor: "pkg-examples", Children: examples})
+	}
+	return index, nil
+}
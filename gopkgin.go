@@ -0,0 +1,79 @@
+package pkggodev
+
+import (
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// gopkgInHost serves GitHub repositories under paths carrying the major
+// version, like gopkg.in/yaml.v3 for the v3 tags of github.com/go-yaml/yaml
+const gopkgInHost = "gopkg.in"
+
+// splitGopkgIn splits a gopkg.in import path into the GitHub owner and
+// repository serving it and its major version, e.g. gopkg.in/yaml.v3/sub ->
+// go-yaml, yaml, v3. ok is false for paths that aren't on gopkg.in.
+func splitGopkgIn(importPath string) (owner, repo, major string, ok bool) {
+	components := strings.Split(importPath, "/")
+	if components[0] != gopkgInHost || len(components) < 2 {
+		return "", "", "", false
+	}
+	// gopkg.in/pkg.v3 is github.com/go-pkg/pkg, gopkg.in/user/pkg.v3 is
+	// github.com/user/pkg
+	name := components[1]
+	if !gopkgInVersion.MatchString(name) {
+		if len(components) < 3 || !gopkgInVersion.MatchString(components[2]) {
+			return "", "", "", false
+		}
+		owner, name = components[1], components[2]
+	}
+	suffix := gopkgInVersion.FindStringIndex(name)
+	repo, major = name[:suffix[0]], name[suffix[0]+1:]
+	if owner == "" {
+		owner = "go-" + repo
+	}
+	return owner, repo, major, true
+}
+
+// gopkgInRepository returns the GitHub repository serving a gopkg.in path,
+// like github.com/go-yaml/yaml for gopkg.in/yaml.v3
+func gopkgInRepository(importPath string) (string, bool) {
+	owner, repo, _, ok := splitGopkgIn(importPath)
+	if !ok {
+		return "", false
+	}
+	return "github.com/" + owner + "/" + repo, true
+}
+
+// gopkgInMajor returns the major version of a gopkg.in path, like v3 for
+// gopkg.in/yaml.v3, or "" for other paths
+func gopkgInMajor(importPath string) string {
+	_, _, major, _ := splitGopkgIn(importPath)
+	return major
+}
+
+// resolveRepository replaces the repository of a gopkg.in package with the
+// GitHub one when it's missing or points back at gopkg.in, which only
+// proxies the repository's git data
+func resolveRepository(importPath, repository string) string {
+	if repository != "" && !hasPathPrefix(strings.TrimPrefix(repository, "https://"), gopkgInHost) {
+		return repository
+	}
+	if repo, ok := gopkgInRepository(importPath); ok {
+		return repo
+	}
+	return repository
+}
+
+// majorVersionLabel returns the major version of a heading of the versions
+// tab, which may name the module path rather than the major, like
+// gopkg.in/yaml.v3 or example.com/mod/v2
+func majorVersionLabel(heading string) string {
+	if !strings.Contains(heading, "/") {
+		return heading
+	}
+	if _, pathMajor, ok := module.SplitPathVersion(heading); ok && pathMajor != "" {
+		return "v" + strings.TrimLeft(pathMajor, "./v")
+	}
+	return heading
+}
@@ -0,0 +1,149 @@
+package pkggodev
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"golang.org/x/mod/module"
+)
+
+// defaultSumDBURL is the checksum database used unless changed with
+// WithSumDBURL
+const defaultSumDBURL = "https://sum.golang.org"
+
+// sumDBAttempts is how many times a lookup is tried when the checksum
+// database rate limits it
+const sumDBAttempts = 3
+
+// maxSumDBBackoff caps the wait asked for by the Retry-After header, and
+// defaultSumDBBackoff is waited when there's none
+const (
+	maxSumDBBackoff     = 30 * time.Second
+	defaultSumDBBackoff = time.Second
+)
+
+// ErrRateLimited is returned when a host kept answering 429 Too Many Requests
+var ErrRateLimited = errors.New("rate limited")
+
+// WithSumDBURL sets the checksum database (as in GOSUMDB) used by FetchGoSum
+func WithSumDBURL(url string) func(c *client) {
+	return func(c *client) {
+		c.sumDBURL = url
+	}
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as a date
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return defaultSumDBBackoff
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return min(time.Duration(max(seconds, 0))*time.Second, maxSumDBBackoff)
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return min(max(time.Until(date), 0), maxSumDBBackoff)
+	}
+	return defaultSumDBBackoff
+}
+
+// fetchSumDB fetches a path from the checksum database, waiting and trying
+// again when rate limited. Unknown modules and versions are reported as
+// ErrNotFound.
+func (c *client) fetchSumDB(ctx context.Context, sumDBPath string) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		col := c.newCollector()
+		col.Context = ctx
+		var body []byte
+		var err error
+		var wait time.Duration
+
+		col.OnResponse(func(r *colly.Response) {
+			body = r.Body
+		})
+		col.OnError(func(r *colly.Response, e error) {
+			switch r.StatusCode {
+			case http.StatusNotFound, http.StatusGone:
+				err = ErrNotFound
+			case http.StatusTooManyRequests:
+				err = fmt.Errorf("%w: %s", ErrRateLimited, r.Request.URL.String())
+				if r.Headers != nil {
+					wait = retryAfter(r.Headers.Get("Retry-After"))
+				} else {
+					wait = defaultSumDBBackoff
+				}
+			default:
+				err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
+			}
+		})
+		visitErr := col.Visit(fmt.Sprintf("%s/%s", c.sumDBURL, sumDBPath))
+		if err == nil && visitErr != nil {
+			err = fmt.Errorf("visiting %s: %w", sumDBPath, visitErr)
+		}
+		if !errors.Is(err, ErrRateLimited) || attempt == sumDBAttempts {
+			return body, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// FetchGoSum returns the go.sum entries of a module version from the
+// checksum database, as the go command would write them: the hash of the
+// module's zip file, then the hash of its go.mod file, each on a line of its
+// own. Lookups are cached like other responses when WithCache is set.
+// Versions unknown to the database are reported as ErrNotFound, and lookups
+// the database still rate limits after a few attempts as ErrRateLimited.
+func (c *client) FetchGoSum(ctx context.Context, modulePath, version string) (string, error) {
+	if err := module.Check(modulePath, version); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	if c.pkgGoDevOnly {
+		return "", ErrExternalFetchDisabled
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	key := modulePath + "@" + version
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	body, err := c.fetchSumDB(ctx, fmt.Sprintf("lookup/%s@%s", escapedPath, escapedVersion))
+	if err != nil {
+		return "", fmt.Errorf("looking up '%s': %w", key, err)
+	}
+
+	// the record is a line with its ID, the go.sum lines and a blank line
+	// followed by the signed tree head
+	var sum strings.Builder
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 3 && fields[0] == modulePath && (fields[1] == version || fields[1] == version+"/go.mod") {
+			sum.WriteString(strings.Join(fields, " ") + "\n")
+		}
+	}
+	if sum.Len() == 0 {
+		return "", fmt.Errorf("%w: no go.sum lines in the record of '%s'", ErrUnexpectedContent, key)
+	}
+	return sum.String(), nil
+}
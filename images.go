@@ -0,0 +1,157 @@
+package pkggodev
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ImageSet is the list of images of a package's README
+type ImageSet []Image
+
+// imageFileName returns a file name for an image: its alt text made safe for
+// file systems, with the extension of its URL
+func imageFileName(name, imageURL string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	safe = strings.Trim(safe, ".")
+	if safe == "" {
+		safe = "image"
+	}
+	if u, err := url.Parse(imageURL); err == nil {
+		safe += path.Ext(u.Path)
+	}
+	return safe
+}
+
+// uniqueName returns name, or name with an index appended when it's already
+// in taken, and marks the result as taken
+func uniqueName(name string, taken map[string]bool, withIndex func(i int) string) string {
+	for i := 1; taken[name]; i++ {
+		name = withIndex(i)
+	}
+	taken[name] = true
+	return name
+}
+
+// DownloadImages saves the images to destDir, which is created if needed,
+// several at once. The returned map goes from alt text to the file an image
+// was saved to; an index is appended to repeated alt texts, like "badge-1",
+// and an empty one is named "image". Images that failed are left out of the
+// map and reported in an ErrorList. Requests go through the client's options,
+// so WithPkgGoDevOnly fails images hosted elsewhere with
+// ErrExternalFetchDisabled.
+func (c *client) DownloadImages(ctx context.Context, s ImageSet, destDir string) (map[string]string, error) {
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating '%s': %w", destDir, err)
+	}
+
+	keys := make([]string, len(s))
+	files := make([]string, len(s))
+	takenKeys, takenFiles := map[string]bool{}, map[string]bool{}
+	for i, img := range s {
+		alt := img.Alt
+		if alt == "" {
+			alt = "image"
+		}
+		keys[i] = uniqueName(alt, takenKeys, func(n int) string {
+			return alt + "-" + strconv.Itoa(n)
+		})
+		// distinct alt texts may still make the same file name
+		file := imageFileName(keys[i], img.URL)
+		ext := filepath.Ext(file)
+		files[i] = filepath.Join(destDir, uniqueName(file, takenFiles, func(n int) string {
+			return strings.TrimSuffix(file, ext) + "-" + strconv.Itoa(n) + ext
+		}))
+	}
+
+	httpClient := c.newHTTPClient()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	saved := map[string]string{}
+	errs := &ErrorList{}
+	sem := make(chan struct{}, defaultBatchConcurrency)
+
+	for i, img := range s {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return saved, ctx.Err()
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := c.downloadImage(ctx, httpClient, img.URL, files[i])
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs.Errs = append(errs.Errs, fmt.Errorf("downloading '%s': %w", img.URL, err))
+				return
+			}
+			saved[keys[i]] = files[i]
+		}()
+	}
+	wg.Wait()
+
+	if len(errs.Errs) > 0 {
+		return saved, errs
+	}
+	return saved, nil
+}
+
+// downloadImage saves the image at imageURL to file, removing the file again
+// when the download fails
+func (c *client) downloadImage(ctx context.Context, httpClient *http.Client, imageURL, file string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return err
+	}
+	if c.isBaseHost(req.URL) {
+		if c.authorization != "" {
+			req.Header.Set("Authorization", c.authorization)
+		}
+	} else if c.pkgGoDevOnly {
+		return ErrExternalFetchDisabled
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil && strings.HasPrefix(mediaType, "text/html") {
+		return fmt.Errorf("%w: got %s instead of an image", ErrUnexpectedContent, mediaType)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file)
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package pkggodev
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// LicenseReport aggregates the licenses of a set of packages
+type LicenseReport struct {
+	Groups       []LicenseGroup `json:"groups"`       // sorted by license
+	Undetermined []string       `json:"undetermined"` // packages without a detected license
+}
+
+// LicenseGroup lists the packages using a license. A package with several
+// licenses appears in each of their groups.
+type LicenseGroup struct {
+	License  string   `json:"license"` // SPDX identifier
+	Copyleft bool     `json:"copyleft"`
+	Packages []string `json:"packages"`
+}
+
+// isCopyleft reports whether an SPDX identifier belongs to the GPL, AGPL or LGPL families
+func isCopyleft(spdxID string) bool {
+	for _, prefix := range []string{"GPL-", "AGPL-", "LGPL-"} {
+		if strings.HasPrefix(spdxID, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLicenses splits pkg.go.dev's comma separated license list, dropping
+// duplicates and the placeholder for unknown licenses
+func splitLicenses(license string) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, id := range strings.Split(license, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" || id == "UNKNOWN" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// LicenseReport describes pkgs concurrently, as many at once as set in
+// opts.Concurrency, and groups them by license. It only aggregates what
+// pkg.go.dev detected, without any legal inference. Packages that couldn't be
+// described are listed as undetermined, and their errors are returned in an
+// ErrorList alongside the report.
+func (c *client) LicenseReport(ctx context.Context, pkgs []string, opts BatchOptions) (*LicenseReport, error) {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = defaultBatchConcurrency
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := &ErrorList{}
+	byLicense := map[string][]string{}
+	var undetermined []string
+	sem := make(chan struct{}, concurrency)
+
+	for _, pkg := range pkgs {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			p, err := c.describePackage(DescribePackageRequest{Package: pkg}, func(col *colly.Collector) {
+				col.Context = ctx
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs.Errs = append(errs.Errs, fmt.Errorf("describing '%s': %w", pkg, err))
+				undetermined = append(undetermined, pkg)
+				return
+			}
+			ids := splitLicenses(p.License)
+			if len(ids) == 0 {
+				undetermined = append(undetermined, pkg)
+				return
+			}
+			for _, id := range ids {
+				byLicense[id] = append(byLicense[id], pkg)
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := &LicenseReport{Undetermined: undetermined}
+	sort.Strings(report.Undetermined)
+	for id, pkgs := range byLicense {
+		sort.Strings(pkgs)
+		report.Groups = append(report.Groups, LicenseGroup{
+			License:  id,
+			Copyleft: isCopyleft(id),
+			Packages: pkgs,
+		})
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return report.Groups[i].License < report.Groups[j].License
+	})
+
+	if len(errs.Errs) > 0 {
+		return report, errs
+	}
+	return report, nil
+}
+
+// WriteCSV writes the report as license,copyleft,package rows, with an empty
+// license for undetermined packages
+func (r *LicenseReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"license", "copyleft", "package"}); err != nil {
+		return err
+	}
+	for _, group := range r.Groups {
+		for _, pkg := range group.Packages {
+			if err := cw.Write([]string{group.License, strconv.FormatBool(group.Copyleft), pkg}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, pkg := range r.Undetermined {
+		if err := cw.Write([]string{"", "false", pkg}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
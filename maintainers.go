@@ -0,0 +1,48 @@
+package pkggodev
+
+import (
+	"strings"
+)
+
+// splitAuthors splits the content of an author meta tag, which may name
+// several people separated by commas or semicolons
+func splitAuthors(content string) []string {
+	var authors []string
+	for _, author := range strings.FieldsFunc(content, func(r rune) bool { return r == ',' || r == ';' }) {
+		if author = strings.TrimSpace(author); author != "" {
+			authors = append(authors, author)
+		}
+	}
+	return authors
+}
+
+// moduleOrganization returns who owns a module path, as told by its module
+// directive: the owner on a known git host or gopkg.in, like "spf13" for
+// github.com/spf13/cobra, otherwise the domain, like "golang.org" for
+// golang.org/x/mod. It's "" for paths without a domain.
+func moduleOrganization(modulePath string) string {
+	if owner, _, _, ok := splitGopkgIn(modulePath); ok {
+		return owner
+	}
+	components := strings.Split(modulePath, "/")
+	host := components[0]
+	if !strings.Contains(host, ".") {
+		return ""
+	}
+	if _, ok := builtinGitHosts[host]; ok && len(components) > 1 {
+		// sourcehut prefixes users with a tilde
+		return strings.TrimPrefix(components[1], "~")
+	}
+	return host
+}
+
+// setMaintainers falls back to the organization of the module when the page
+// named no authors
+func (p *Package) setMaintainers() {
+	if len(p.Maintainers) > 0 {
+		return
+	}
+	if org := moduleOrganization(p.modulePath()); org != "" {
+		p.Maintainers = []string{org}
+	}
+}
@@ -0,0 +1,77 @@
+package pkggodev
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// ClientMetrics is a snapshot of the client's counters since it was created or
+// last reset. TotalDuration is the time spent waiting on requests, in
+// nanoseconds. The cache counters stay at zero unless WithCache is used:
+// requests answered from the cache are hits, ones the server confirmed
+// unchanged with a 304 are revalidations and the others are misses.
+type ClientMetrics struct {
+	TotalRequests      uint64
+	CacheHits          uint64
+	CacheMisses        uint64
+	CacheRevalidations uint64
+	Errors             uint64
+	TotalDuration      uint64
+}
+
+type clientMetrics struct {
+	totalRequests      atomic.Uint64
+	cacheHits          atomic.Uint64
+	cacheMisses        atomic.Uint64
+	cacheRevalidations atomic.Uint64
+	errors             atomic.Uint64
+	totalDuration      atomic.Uint64
+}
+
+// metricsStartKey is the colly request context key holding the request start time
+const metricsStartKey = "pkggodev.metricsStart"
+
+// trackMetrics updates the client's counters for every request made by col
+func (c *client) trackMetrics(col *colly.Collector) {
+	col.OnRequest(func(r *colly.Request) {
+		c.metrics.totalRequests.Add(1)
+		r.Ctx.Put(metricsStartKey, time.Now())
+	})
+	col.OnResponse(func(r *colly.Response) {
+		c.addRequestDuration(r)
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		c.metrics.errors.Add(1)
+		c.addRequestDuration(r)
+	})
+}
+
+func (c *client) addRequestDuration(r *colly.Response) {
+	if start, ok := r.Ctx.GetAny(metricsStartKey).(time.Time); ok {
+		c.metrics.totalDuration.Add(uint64(time.Since(start)))
+	}
+}
+
+// Metrics returns a snapshot of the client's request counters
+func (c *client) Metrics() ClientMetrics {
+	return ClientMetrics{
+		TotalRequests:      c.metrics.totalRequests.Load(),
+		CacheHits:          c.metrics.cacheHits.Load(),
+		CacheMisses:        c.metrics.cacheMisses.Load(),
+		CacheRevalidations: c.metrics.cacheRevalidations.Load(),
+		Errors:             c.metrics.errors.Load(),
+		TotalDuration:      c.metrics.totalDuration.Load(),
+	}
+}
+
+// ResetMetrics zeroes the client's request counters
+func (c *client) ResetMetrics() {
+	c.metrics.totalRequests.Store(0)
+	c.metrics.cacheHits.Store(0)
+	c.metrics.cacheMisses.Store(0)
+	c.metrics.cacheRevalidations.Store(0)
+	c.metrics.errors.Store(0)
+	c.metrics.totalDuration.Store(0)
+}
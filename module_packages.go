@@ -0,0 +1,200 @@
+package pkggodev
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+// defaultBatchConcurrency is used when BatchOptions.Concurrency isn't set
+const defaultBatchConcurrency = 4
+
+// Directory is an entry of the Directories section of a module's page
+type Directory struct {
+	Path     string
+	Synopsis string
+	IsModule bool // a nested module rather than a package of the module
+}
+
+// BatchOptions configures methods that describe many packages at once
+type BatchOptions struct {
+	Concurrency          int  // packages described at once, defaults to 4
+	ExcludeNestedModules bool // skip directories that are modules of their own
+	ExcludeInternal      bool // skip packages under an internal/ directory
+}
+
+// directoryVersionPattern matches the @version element of directory links
+var directoryVersionPattern = regexp.MustCompile(`@[^/]*`)
+
+// isInternal reports whether an import path has an internal element
+func isInternal(importPath string) bool {
+	for _, elem := range strings.Split(importPath, "/") {
+		if elem == "internal" {
+			return true
+		}
+	}
+	return false
+}
+
+// Directories lists the directories of a module as shown on its page
+func (c *client) Directories(ctx context.Context, modulePath string) ([]Directory, error) {
+	dirs, _, err := c.moduleDirectories(ctx, modulePath)
+	return dirs, err
+}
+
+// DescribeModule describes the module providing path, which may be the module
+// path itself, a package or a command of the module, or a pkg.go.dev URL of
+// any of them. The module path is resolved with the module proxy, falling
+// back on the unit header of path when the proxy can't be reached. The
+// returned Package holds the resolved module path in its Package field.
+func (c *client) DescribeModule(ctx context.Context, path string) (*Package, error) {
+	path, version := c.splitPagePath(path)
+	root, err := c.ResolveModuleForPackage(ctx, path)
+	if err != nil {
+		p, describeErr := c.describePackage(DescribePackageRequest{Package: path, Version: version}, func(col *colly.Collector) {
+			col.Context = ctx
+		})
+		if describeErr != nil {
+			return nil, describeErr
+		}
+		if p.IsModule {
+			return p, nil
+		}
+		root = p.modulePath()
+	}
+
+	p, err := c.describePackage(DescribePackageRequest{Package: root, Version: version}, func(col *colly.Collector) {
+		col.Context = ctx
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing module '%s': %w", root, err)
+	}
+	if !p.IsModule {
+		return nil, fmt.Errorf("%w: '%s' resolved to '%s', which isn't shown as a module", ErrUnexpectedContent, path, root)
+	}
+	return p, nil
+}
+
+// moduleDirectories scrapes the Directories section of a module's page, also
+// reporting whether the module root is itself a package
+func (c *client) moduleDirectories(ctx context.Context, modulePath string) ([]Directory, bool, error) {
+	if c.baseURLErr != nil {
+		return nil, false, c.baseURLErr
+	}
+	col := c.newCollector()
+	col.Context = ctx
+	sel := c.selectors
+	var dirs []Directory
+	var rootIsPackage bool
+	seen := map[string]bool{}
+	var err error
+
+	col.OnHTML(sel.UnitTitle, func(e *colly.HTMLElement) {
+		e.DOM.NextAll().Each(func(_ int, chip *goquery.Selection) {
+			if strings.TrimSpace(chip.Text()) == "package" {
+				rootIsPackage = true
+			}
+		})
+	})
+	col.OnHTML(sel.UnitDirectoryRow, func(e *colly.HTMLElement) {
+		href, ok := e.DOM.Find(sel.UnitDirectoryLink).First().Attr("href")
+		if !ok {
+			return
+		}
+		link, parseErr := url.Parse(resolveURL(e.Request.URL, href))
+		if parseErr != nil {
+			return
+		}
+		dirPath := directoryVersionPattern.ReplaceAllString(strings.Trim(link.Path, "/"), "")
+		if dirPath == modulePath || seen[dirPath] {
+			return
+		}
+		seen[dirPath] = true
+		isModule := false
+		e.DOM.Find(sel.UnitDirectoryBadge).Each(func(_ int, chip *goquery.Selection) {
+			if strings.TrimSpace(chip.Text()) == "module" {
+				isModule = true
+			}
+		})
+		dirs = append(dirs, Directory{
+			Path:     dirPath,
+			Synopsis: strings.TrimSpace(e.DOM.Find(sel.UnitDirectorySynopsis).First().Text()),
+			IsModule: isModule,
+		})
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		if r.StatusCode == 404 {
+			err = ErrNotFound
+			return
+		}
+		err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
+	})
+	col.Visit(c.pageURL(modulePath))
+	if err != nil {
+		return nil, false, err
+	}
+	return dirs, rootIsPackage, nil
+}
+
+// DescribeModulePackages describes every package of a module, in the order of
+// the module's directory listing with the module root first when it is a
+// package. The returned errors align with the packages: errs[i] is set when
+// packages[i] couldn't be described. When the listing itself can't be
+// fetched, no packages and a single error are returned.
+func (c *client) DescribeModulePackages(ctx context.Context, modulePath string, opts BatchOptions) ([]*Package, []error) {
+	dirs, rootIsPackage, err := c.moduleDirectories(ctx, modulePath)
+	if err != nil {
+		return nil, []error{fmt.Errorf("listing directories of '%s': %w", modulePath, err)}
+	}
+
+	var pkgs []string
+	if rootIsPackage {
+		pkgs = append(pkgs, modulePath)
+	}
+	for _, dir := range dirs {
+		if opts.ExcludeNestedModules && dir.IsModule {
+			continue
+		}
+		if opts.ExcludeInternal && isInternal(strings.TrimPrefix(dir.Path, modulePath)) {
+			continue
+		}
+		pkgs = append(pkgs, dir.Path)
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = defaultBatchConcurrency
+	}
+	packages := make([]*Package, len(pkgs))
+	errs := make([]error, len(pkgs))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for i, pkg := range pkgs {
+		select {
+		case <-ctx.Done():
+			for j := i; j < len(pkgs); j++ {
+				errs[j] = ctx.Err()
+			}
+			wg.Wait()
+			return packages, errs
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			packages[i], errs[i] = c.describePackage(DescribePackageRequest{Package: pkg}, func(col *colly.Collector) {
+				col.Context = ctx
+			})
+		}()
+	}
+	wg.Wait()
+	return packages, errs
+}
@@ -0,0 +1,150 @@
+package pkggodev
+
+import (
+	"slices"
+)
+
+// DiffField names a field of Package compared by DiffPackages
+type DiffField string
+
+const (
+	DiffVersion                   DiffField = "Version"
+	DiffLatestVersion             DiffField = "LatestVersion"
+	DiffPublished                 DiffField = "Published"
+	DiffLicense                   DiffField = "License"
+	DiffHasRedistributableLicense DiffField = "HasRedistributableLicense"
+	DiffHasValidGoModFile         DiffField = "HasValidGoModFile"
+	DiffHasTaggedVersion          DiffField = "HasTaggedVersion"
+	DiffHasStableVersion          DiffField = "HasStableVersion"
+	DiffRepository                DiffField = "Repository"
+	DiffMajorVersions             DiffField = "MajorVersions"
+	DiffSynopsis                  DiffField = "Synopsis"
+	DiffDocumentedSymbolCount     DiffField = "DocumentedSymbolCount"
+	DiffImportedByCount           DiffField = "ImportedByCount"
+	DiffDeprecated                DiffField = "Deprecated"
+	DiffSupersededBy              DiffField = "SupersededBy"
+	DiffIsArchived                DiffField = "IsArchived"
+	DiffStars                     DiffField = "Stars"
+	DiffForkCount                 DiffField = "ForkCount"
+	DiffWatcherCount              DiffField = "WatcherCount"
+	DiffIssueCount                DiffField = "IssueCount"
+	DiffContributorCount          DiffField = "ContributorCount"
+	DiffCoveragePercent           DiffField = "CoveragePercent"
+	DiffMaintainers               DiffField = "Maintainers"
+)
+
+// importedByJump is the relative change of ImportedByCount, from the old
+// count, that makes it material
+const importedByJump = 0.1
+
+// FieldChange is the change of one field between two descriptions of a
+// package. Old and New hold the field's values: a string, bool, int, float64
+// or []string.
+type FieldChange struct {
+	Field    DiffField `json:"field"`
+	Old      any       `json:"old"`
+	New      any       `json:"new"`
+	Material bool      `json:"material"` // see PackageDiff.HasMaterialChanges
+}
+
+// PackageDiff lists the fields that changed between two descriptions of a
+// package, in the order of DiffField's constants
+type PackageDiff struct {
+	Package string        `json:"package"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// HasMaterialChanges reports whether a change matters to users of the
+// package rather than being informational. Material changes are those of
+// Version, License, HasRedistributableLicense, Repository, MajorVersions,
+// Deprecated, SupersededBy and IsArchived, and of ImportedByCount when it
+// moved by at least 10% of the old count.
+func (d PackageDiff) HasMaterialChanges() bool {
+	for _, change := range d.Changes {
+		if change.Material {
+			return true
+		}
+	}
+	return false
+}
+
+// diffedField is a field compared by DiffPackages. material is nil for
+// informational fields.
+type diffedField struct {
+	field    DiffField
+	value    func(p *Package) any
+	material func(old, new *Package) bool
+}
+
+func alwaysMaterial(_, _ *Package) bool { return true }
+
+var diffedFields = []diffedField{
+	{DiffVersion, func(p *Package) any { return p.Version }, alwaysMaterial},
+	{DiffLatestVersion, func(p *Package) any { return p.LatestVersion }, nil},
+	{DiffPublished, func(p *Package) any { return p.Published }, nil},
+	{DiffLicense, func(p *Package) any { return p.License }, alwaysMaterial},
+	{DiffHasRedistributableLicense, func(p *Package) any { return p.HasRedistributableLicense }, alwaysMaterial},
+	{DiffHasValidGoModFile, func(p *Package) any { return p.HasValidGoModFile }, nil},
+	{DiffHasTaggedVersion, func(p *Package) any { return p.HasTaggedVersion }, nil},
+	{DiffHasStableVersion, func(p *Package) any { return p.HasStableVersion }, nil},
+	{DiffRepository, func(p *Package) any { return p.Repository }, alwaysMaterial},
+	{DiffMajorVersions, func(p *Package) any { return p.MajorVersions }, alwaysMaterial},
+	{DiffSynopsis, func(p *Package) any { return p.Synopsis }, nil},
+	{DiffDocumentedSymbolCount, func(p *Package) any { return p.DocumentedSymbolCount }, nil},
+	{DiffImportedByCount, func(p *Package) any { return p.ImportedByCount }, func(old, new *Package) bool {
+		delta := float64(new.ImportedByCount - old.ImportedByCount)
+		return max(delta, -delta) >= importedByJump*float64(old.ImportedByCount)
+	}},
+	{DiffDeprecated, func(p *Package) any { return p.Deprecated }, alwaysMaterial},
+	{DiffSupersededBy, func(p *Package) any { return p.SupersededBy }, alwaysMaterial},
+	{DiffIsArchived, func(p *Package) any { return p.IsArchived }, alwaysMaterial},
+	{DiffStars, func(p *Package) any { return p.Stars }, nil},
+	{DiffForkCount, func(p *Package) any { return p.ForkCount }, nil},
+	{DiffWatcherCount, func(p *Package) any { return p.WatcherCount }, nil},
+	{DiffIssueCount, func(p *Package) any { return p.IssueCount }, nil},
+	{DiffContributorCount, func(p *Package) any { return p.ContributorCount }, nil},
+	{DiffCoveragePercent, func(p *Package) any { return p.CoveragePercent }, nil},
+	{DiffMaintainers, func(p *Package) any { return p.Maintainers }, nil},
+}
+
+// equalValues compares field values, with nil and empty slices being equal
+func equalValues(a, b any) bool {
+	if as, ok := a.([]string); ok {
+		return slices.Equal(as, b.([]string))
+	}
+	return a == b
+}
+
+// DiffPackages compares two descriptions of the same package, like daily
+// snapshots, returning the fields whose value changed. Published is only
+// compared when Version changed too: pkg.go.dev shows recent dates relative
+// to today, so the date parsed for an unchanged version may drift. A nil old
+// is compared as an empty Package, making every field set in new a change.
+func DiffPackages(old, new *Package) PackageDiff {
+	if old == nil {
+		old = &Package{}
+	}
+	if new == nil {
+		new = &Package{}
+	}
+	diff := PackageDiff{Package: new.Package, Changes: []FieldChange{}}
+	if diff.Package == "" {
+		diff.Package = old.Package
+	}
+	for _, f := range diffedFields {
+		if f.field == DiffPublished && old.Version == new.Version {
+			continue
+		}
+		oldValue, newValue := f.value(old), f.value(new)
+		if equalValues(oldValue, newValue) {
+			continue
+		}
+		diff.Changes = append(diff.Changes, FieldChange{
+			Field:    f.field,
+			Old:      oldValue,
+			New:      newValue,
+			Material: f.material != nil && f.material(old, new),
+		})
+	}
+	return diff
+}
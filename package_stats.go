@@ -0,0 +1,133 @@
+package pkggodev
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+	"golang.org/x/mod/modfile"
+)
+
+// PackageStats are counts describing a package at a glance
+type PackageStats struct {
+	ImportedByCount   int
+	DirectDepsCount   int // requirements of the module's go.mod, from its latest version
+	IndirectDepsCount int // requirements marked // indirect
+	VersionCount      int
+	ExampleCount      int
+	SymbolCount       int // documented functions, types, methods, constants and variables
+}
+
+// Stats gathers the counts of pkg from its unit page, its versions tab and
+// its module's go.mod on the module proxy, fetched concurrently. When only
+// some of them could be fetched, the counts found are returned along with
+// an ErrorList of the failures.
+func (c *client) Stats(ctx context.Context, pkg string) (*PackageStats, error) {
+	return c.gatherStats(ctx, pkg, func(stats *PackageStats) error {
+		return c.scrapeUnitStats(ctx, pkg, stats)
+	})
+}
+
+// gatherStats gathers the counts of pkg like Stats, with unit filling in
+// those of the unit page
+func (c *client) gatherStats(ctx context.Context, pkg string, unit func(stats *PackageStats) error) (*PackageStats, error) {
+	stats := &PackageStats{}
+	var unitErr, versionsErr, goModErr error
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		unitErr = unit(stats)
+	}()
+	go func() {
+		defer wg.Done()
+		versions, err := c.Versions(VersionsRequest{Package: pkg})
+		if err != nil {
+			versionsErr = fmt.Errorf("listing versions: %w", err)
+			return
+		}
+		stats.VersionCount = len(versions.Versions)
+	}()
+	go func() {
+		defer wg.Done()
+		goModErr = c.countDeps(ctx, pkg, stats)
+	}()
+	wg.Wait()
+
+	if unitErr != nil {
+		return nil, unitErr
+	}
+	errs := &ErrorList{}
+	for _, err := range []error{versionsErr, goModErr} {
+		if err != nil {
+			errs.Errs = append(errs.Errs, err)
+		}
+	}
+	if len(errs.Errs) > 0 {
+		return stats, errs
+	}
+	return stats, nil
+}
+
+// scrapeUnitStats fills in the counts of stats found on the unit page
+func (c *client) scrapeUnitStats(ctx context.Context, pkg string, stats *PackageStats) error {
+	if c.baseURLErr != nil {
+		return c.baseURLErr
+	}
+	col := c.newCollector()
+	col.Context = ctx
+	var err error
+
+	trackUnitStats(col, c.selectors, stats)
+	col.OnError(func(r *colly.Response, e error) {
+		if r.StatusCode == 404 {
+			err = ErrNotFound
+			return
+		}
+		err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
+	})
+	col.Visit(c.pageURL(pkg))
+	return err
+}
+
+// trackUnitStats fills in the counts of stats from the unit pages col visits
+func trackUnitStats(col *colly.Collector, sel Selectors, stats *PackageStats) {
+	col.OnHTML(sel.UnitImportedBy, func(e *colly.HTMLElement) {
+		stats.ImportedByCount, _ = parseCount(e.Text)
+	})
+	col.OnHTML(sel.DocExample, func(e *colly.HTMLElement) {
+		stats.ExampleCount++
+	})
+	for _, selector := range []string{sel.DocIndexFunction, sel.DocIndexType, sel.DocIndexMethod, sel.DocConstant, sel.DocVariable} {
+		col.OnHTML(selector, func(e *colly.HTMLElement) {
+			stats.SymbolCount++
+		})
+	}
+}
+
+// countDeps fills in the dependency counts of stats from the go.mod of the
+// latest version of pkg's module
+func (c *client) countDeps(ctx context.Context, pkg string, stats *PackageStats) error {
+	modulePath, info, err := c.findModuleInfo(ctx, pkg, "@latest")
+	if err != nil {
+		return fmt.Errorf("resolving module: %w", err)
+	}
+	body, err := c.FetchGoMod(ctx, modulePath, info.Version)
+	if err != nil {
+		return err
+	}
+	goMod, err := modfile.ParseLax("go.mod", []byte(body), nil)
+	if err != nil {
+		return fmt.Errorf("parsing go.mod of '%s@%s': %w", modulePath, info.Version, err)
+	}
+	for _, req := range goMod.Require {
+		if req.Indirect {
+			stats.IndirectDepsCount++
+		} else {
+			stats.DirectDepsCount++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package pkggodev
+
+import (
+	"context"
+)
+
+// pageIterator runs a scrape in a goroutine of its own, handing over the
+// entries it parses one at a time. The goroutine starts on the first call to
+// next and ends once the page is parsed or close is called, which cancels
+// the request if it's still in flight.
+type pageIterator[T any] struct {
+	run    func(ctx context.Context, emit func(T) bool) error
+	ctx    context.Context
+	cancel context.CancelFunc
+	items  chan T
+	err    error // set by the goroutine before closing items
+
+	started  bool
+	finished bool // items was drained, err can be read
+}
+
+func newPageIterator[T any](ctx context.Context, run func(ctx context.Context, emit func(T) bool) error) *pageIterator[T] {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &pageIterator[T]{run: run, ctx: ctx, cancel: cancel, items: make(chan T)}
+}
+
+// next returns the next entry, or false once there are none left
+func (it *pageIterator[T]) next() (T, bool, error) {
+	if !it.started {
+		it.started = true
+		go func() {
+			defer close(it.items)
+			it.err = it.run(it.ctx, func(item T) bool {
+				select {
+				case it.items <- item:
+					return true
+				case <-it.ctx.Done():
+					return false
+				}
+			})
+		}()
+	}
+	var zero T
+	if it.finished {
+		return zero, false, it.err
+	}
+	if item, ok := <-it.items; ok {
+		return item, true, nil
+	}
+	it.finished = true
+	it.cancel()
+	return zero, false, it.err
+}
+
+// close stops the goroutine and waits for it to end. The error of a scrape
+// cut short is dropped.
+func (it *pageIterator[T]) close() {
+	it.cancel()
+	if it.started && !it.finished {
+		for range it.items {
+		}
+	}
+	it.started, it.finished, it.err = true, true, nil
+}
+
+// ImportedByIterator goes through the importers of a package as the
+// importedby tab is parsed, see ImportedByIter
+type ImportedByIterator struct {
+	it *pageIterator[Importer]
+}
+
+// ImportedByIter returns an iterator over the importers of req.Package, for
+// callers that may stop early, like when checking that at least 100 exist.
+// The page is fetched and parsed by a goroutine started on the first call to
+// Next, which hands over one importer at a time and is done once they're
+// all consumed. Call Close when stopping early: it cancels the request if
+// it's still in flight and ends the goroutine. The iterator is single-use
+// and, like the importers it returns, belongs to the calling goroutine.
+// Stats and Response aren't available through it.
+func (c *client) ImportedByIter(req ImportedByRequest) *ImportedByIterator {
+	return &ImportedByIterator{it: newPageIterator(req.Context, func(ctx context.Context, emit func(Importer) bool) error {
+		req.Context = ctx
+		_, err := c.scrapeImportedBy(req, emit)
+		return err
+	})}
+}
+
+// Next returns the next importer, or nil once there are none left along with
+// the error that stopped the iteration, if any, which Err returns too
+func (i *ImportedByIterator) Next() (*Importer, error) {
+	item, ok, err := i.it.next()
+	if !ok {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Err returns the error that stopped the iteration, once Next returned nil
+func (i *ImportedByIterator) Err() error {
+	if !i.it.finished {
+		return nil
+	}
+	return i.it.err
+}
+
+// Close stops the iteration, aborting the request if it's still in flight.
+// It's safe to call more than once and after the last importer.
+func (i *ImportedByIterator) Close() {
+	i.it.close()
+}
+
+// VersionsIterator goes through the versions of a package as the versions
+// tab is parsed, see VersionsIter
+type VersionsIterator struct {
+	it *pageIterator[Version]
+}
+
+// VersionsIter returns an iterator over the versions of req.Package, newest
+// first as listed on pkg.go.dev, for callers that may stop early, like when
+// looking for the first version older than a date. It works like
+// ImportedByIter: a goroutine started on the first call to Next fetches and
+// parses the page, Close aborts it, and the iterator is single-use. A
+// version failing WithStrictVersions stops the iteration with its error.
+// Warnings, Stats and Response aren't available through it.
+func (c *client) VersionsIter(req VersionsRequest) *VersionsIterator {
+	return &VersionsIterator{it: newPageIterator(req.Context, func(ctx context.Context, emit func(Version) bool) error {
+		req.Context = ctx
+		_, err := c.scrapeVersions(req, emit)
+		return err
+	})}
+}
+
+// Next returns the next version, or nil once there are none left along with
+// the error that stopped the iteration, if any, which Err returns too
+func (i *VersionsIterator) Next() (*Version, error) {
+	item, ok, err := i.it.next()
+	if !ok {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Err returns the error that stopped the iteration, once Next returned nil
+func (i *VersionsIterator) Err() error {
+	if !i.it.finished {
+		return nil
+	}
+	return i.it.err
+}
+
+// Close stops the iteration, aborting the request if it's still in flight.
+// It's safe to call more than once and after the last version.
+func (i *VersionsIterator) Close() {
+	i.it.close()
+}
@@ -0,0 +1,135 @@
+package pkggodev
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// snapshotSchema is the version of the format written by SaveSnapshot. It's
+// only raised by changes older code can't load: fields added to the saved
+// results keep it, as missing fields load as zero values and unknown ones
+// are ignored.
+const snapshotSchema = 1
+
+// ErrUnsupportedSnapshot is returned, wrapped with the details, for values
+// SaveSnapshot can't save and snapshots LoadSnapshot can't load
+var ErrUnsupportedSnapshot = errors.New("unsupported snapshot")
+
+// snapshot is the JSON document written by SaveSnapshot
+type snapshot struct {
+	Schema  int             `json:"schema"`
+	Type    string          `json:"type"` // "Package", "Versions" or "ImportedBy"
+	SavedAt time.Time       `json:"savedAt"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// packageRecord is a Package as saved, with its warnings as messages
+type packageRecord struct {
+	*Package
+	Warnings    []string `json:"Warnings,omitempty"`
+	HasCoverage bool     `json:"HasCoverage,omitempty"`
+}
+
+// versionsRecord is a Versions as saved, with its warnings as messages
+type versionsRecord struct {
+	*Versions
+	Warnings []string `json:"Warnings,omitempty"`
+}
+
+func warningMessages(warnings []error) []string {
+	var messages []string
+	for _, warning := range warnings {
+		messages = append(messages, warning.Error())
+	}
+	return messages
+}
+
+func warningErrors(messages []string) []error {
+	var warnings []error
+	for _, message := range messages {
+		warnings = append(warnings, errors.New(message))
+	}
+	return warnings
+}
+
+// SaveSnapshot writes a *Package, *Versions or *ImportedBy to w as JSON,
+// tagged with its type and the version of the format, to be read back with
+// LoadSnapshot by this or a later release. Warnings are saved as their
+// messages only.
+func SaveSnapshot(w io.Writer, v any) error {
+	var typeName string
+	var record any
+	switch v := v.(type) {
+	case *Package:
+		if v != nil {
+			record = packageRecord{Package: v, Warnings: warningMessages(v.Warnings), HasCoverage: v.hasCoverage}
+		}
+		typeName = "Package"
+	case *Versions:
+		if v != nil {
+			record = versionsRecord{Versions: v, Warnings: warningMessages(v.Warnings)}
+		}
+		typeName = "Versions"
+	case *ImportedBy:
+		if v != nil {
+			record = v
+		}
+		typeName = "ImportedBy"
+	default:
+		return fmt.Errorf("%w: can't save %T", ErrUnsupportedSnapshot, v)
+	}
+	if record == nil {
+		return fmt.Errorf("%w: can't save a nil %s", ErrUnsupportedSnapshot, typeName)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", typeName, err)
+	}
+	return json.NewEncoder(w).Encode(snapshot{Schema: snapshotSchema, Type: typeName, SavedAt: time.Now().UTC(), Data: data})
+}
+
+// LoadSnapshot reads a snapshot written by SaveSnapshot, returning the
+// *Package, *Versions or *ImportedBy it holds. Snapshots of older releases
+// load with the fields they lack at their zero value, and fields unknown to
+// this release are ignored; only a newer version of the format fails, with
+// ErrUnsupportedSnapshot. Warnings load as plain errors holding the saved
+// messages.
+func LoadSnapshot(r io.Reader) (any, error) {
+	var snap snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("decoding snapshot: %w", err)
+	}
+	if snap.Schema < 1 || snap.Schema > snapshotSchema {
+		return nil, fmt.Errorf("%w: format version %d, this release reads up to %d", ErrUnsupportedSnapshot, snap.Schema, snapshotSchema)
+	}
+
+	switch snap.Type {
+	case "Package":
+		record := packageRecord{Package: &Package{}}
+		if err := json.Unmarshal(snap.Data, &record); err != nil {
+			return nil, fmt.Errorf("decoding Package: %w", err)
+		}
+		record.Package.Warnings = warningErrors(record.Warnings)
+		record.Package.hasCoverage = record.HasCoverage
+		return record.Package, nil
+	case "Versions":
+		record := versionsRecord{Versions: &Versions{}}
+		if err := json.Unmarshal(snap.Data, &record); err != nil {
+			return nil, fmt.Errorf("decoding Versions: %w", err)
+		}
+		record.Versions.Warnings = warningErrors(record.Warnings)
+		return record.Versions, nil
+	case "ImportedBy":
+		importedBy := &ImportedBy{}
+		if err := json.Unmarshal(snap.Data, importedBy); err != nil {
+			return nil, fmt.Errorf("decoding ImportedBy: %w", err)
+		}
+		return importedBy, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown type '%s'", ErrUnsupportedSnapshot, snap.Type)
+	}
+}
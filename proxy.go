@@ -0,0 +1,216 @@
+package pkggodev
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// defaultProxyURL is the module proxy used unless changed with WithProxyURL
+const defaultProxyURL = "https://proxy.golang.org"
+
+// WithProxyURL sets the module proxy (as in GOPROXY) used for lookups that
+// pkg.go.dev's pages can't answer
+func WithProxyURL(url string) func(c *client) {
+	return func(c *client) {
+		c.proxyURL = url
+	}
+}
+
+// proxyInfo is the JSON returned by the proxy's .info endpoints
+type proxyInfo struct {
+	Version string
+	Time    time.Time
+}
+
+// fetchProxy fetches a path from the module proxy. The proxy answers 404 or
+// 410 for unknown modules and versions, both are reported as ErrNotFound.
+func (c *client) fetchProxy(ctx context.Context, proxyPath string) ([]byte, error) {
+	if c.pkgGoDevOnly {
+		return nil, ErrExternalFetchDisabled
+	}
+	col := c.newCollector()
+	col.Context = ctx
+	var body []byte
+	var err error
+
+	col.OnResponse(func(r *colly.Response) {
+		body = r.Body
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		if r.StatusCode == 404 || r.StatusCode == 410 {
+			err = ErrNotFound
+			return
+		}
+		err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
+	})
+	visitErr := col.Visit(fmt.Sprintf("%s/%s", c.proxyURL, proxyPath))
+	if err == nil && visitErr != nil {
+		err = fmt.Errorf("visiting %s: %w", proxyPath, visitErr)
+	}
+	return body, err
+}
+
+// findModuleInfo fetches the version info at the proxy endpoint query, like
+// "@latest" or "@v/master.info", of the module providing pkg. The module path
+// isn't known up front, so pkg and then each of its parents is tried as the
+// module path.
+func (c *client) findModuleInfo(ctx context.Context, pkg, query string) (string, proxyInfo, error) {
+	for modulePath := pkg; modulePath != "." && modulePath != "/"; modulePath = path.Dir(modulePath) {
+		escapedPath, err := module.EscapePath(modulePath)
+		if err != nil {
+			break
+		}
+		body, err := c.fetchProxy(ctx, fmt.Sprintf("%s/%s", escapedPath, query))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", proxyInfo{}, err
+		}
+		var info proxyInfo
+		if err := json.Unmarshal(body, &info); err != nil {
+			return "", proxyInfo{}, fmt.Errorf("parsing version info of '%s/%s': %w", modulePath, query, err)
+		}
+		return modulePath, info, nil
+	}
+	return "", proxyInfo{}, ErrNotFound
+}
+
+// resolveRef resolves a version query such as a branch name to a version of
+// the module providing pkg
+func (c *client) resolveRef(ctx context.Context, pkg, ref string) (string, error) {
+	escapedRef, err := module.EscapeVersion(ref)
+	if err != nil {
+		return "", fmt.Errorf("escaping version '%s': %w", ref, err)
+	}
+	_, info, err := c.findModuleInfo(ctx, pkg, fmt.Sprintf("@v/%s.info", escapedRef))
+	if err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+// ResolveModuleForPackage returns the path of the module providing pkg: the
+// longest of pkg and its parents that the module proxy knows as a module
+func (c *client) ResolveModuleForPackage(ctx context.Context, pkg string) (string, error) {
+	modulePath, _, err := c.findModuleInfo(ctx, pkg, "@latest")
+	if err != nil {
+		return "", fmt.Errorf("resolving module of '%s': %w", pkg, err)
+	}
+	return modulePath, nil
+}
+
+// proxyVersions lists the tagged versions of the module providing pkg from
+// the module proxy, newest first like pkg.go.dev does. The list carries no
+// dates, so each version's .info is fetched too.
+func (c *client) proxyVersions(ctx context.Context, pkg string) (*Versions, error) {
+	modulePath, err := c.ResolveModuleForPackage(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return nil, fmt.Errorf("escaping module path '%s': %w", modulePath, err)
+	}
+	body, err := c.fetchProxy(ctx, escapedPath+"/@v/list")
+	if err != nil {
+		return nil, fmt.Errorf("listing versions of '%s': %w", modulePath, err)
+	}
+	list := strings.Fields(string(body))
+	semver.Sort(list)
+	slices.Reverse(list)
+
+	versions := &Versions{Package: pkg, Versions: make([]Version, len(list))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := &ErrorList{}
+	sem := make(chan struct{}, defaultBatchConcurrency)
+	for i, version := range list {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			versions.Versions[i] = Version{MajorVersion: semver.Major(version), FullVersion: version}
+			info, err := c.versionInfo(ctx, escapedPath, version)
+			if err != nil {
+				mu.Lock()
+				errs.Errs = append(errs.Errs, fmt.Errorf("dating version '%s': %w", version, err))
+				mu.Unlock()
+				return
+			}
+			versions.Versions[i].Date = info.Time.In(c.location).Format(time.DateOnly)
+		}()
+	}
+	wg.Wait()
+
+	if len(errs.Errs) > 0 {
+		return nil, errs
+	}
+	return versions, nil
+}
+
+// versionInfo fetches the .info of a version of a module
+func (c *client) versionInfo(ctx context.Context, escapedPath, version string) (proxyInfo, error) {
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return proxyInfo{}, fmt.Errorf("escaping version '%s': %w", version, err)
+	}
+	body, err := c.fetchProxy(ctx, fmt.Sprintf("%s/@v/%s.info", escapedPath, escapedVersion))
+	if err != nil {
+		return proxyInfo{}, err
+	}
+	var info proxyInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return proxyInfo{}, fmt.Errorf("parsing version info: %w", err)
+	}
+	return info, nil
+}
+
+// FetchGoMod returns the go.mod of a module at a version, as served by the
+// module proxy. Responses go through the cache set with WithCache.
+func (c *client) FetchGoMod(ctx context.Context, modulePath, version string) (string, error) {
+	if !semver.IsValid(version) {
+		return "", fmt.Errorf("%w: '%s' is not a version", ErrInvalidRequest, version)
+	}
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", fmt.Errorf("%w: escaping module path '%s': %v", ErrInvalidRequest, modulePath, err)
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("%w: escaping version '%s': %v", ErrInvalidRequest, version, err)
+	}
+	body, err := c.fetchProxy(ctx, fmt.Sprintf("%s/@v/%s.mod", escapedPath, escapedVersion))
+	if err != nil {
+		return "", fmt.Errorf("fetching go.mod of '%s@%s': %w", modulePath, version, err)
+	}
+	// lax parsing skips unknown directives, so check the module directive
+	// to tell a go.mod from some other page
+	goMod, err := modfile.ParseLax("go.mod", body, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: parsing go.mod of '%s@%s': %v", ErrUnexpectedContent, modulePath, version, err)
+	}
+	if goMod.Module == nil {
+		return "", fmt.Errorf("%w: go.mod of '%s@%s' has no module directive", ErrUnexpectedContent, modulePath, version)
+	}
+	return string(body), nil
+}
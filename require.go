@@ -0,0 +1,84 @@
+package pkggodev
+
+import (
+	"fmt"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// requireVersion returns version as required from modulePath in a go.mod:
+// v2 and above of a module without a major version suffix are
+// +incompatible
+func requireVersion(modulePath, version string) string {
+	_, pathMajor, ok := module.SplitPathVersion(modulePath)
+	major := semver.Major(version)
+	if !ok || pathMajor != "" || major == "" || major == "v0" || major == "v1" {
+		return version
+	}
+	if semver.Build(version) == "" {
+		return version + "+incompatible"
+	}
+	return version
+}
+
+// RequireDirective returns the go.mod line requiring module at v, like
+// "require example.com/mod/v2 v2.1.0"
+func (v *Version) RequireDirective(module string) string {
+	return fmt.Sprintf("require %s %s", module, requireVersion(module, v.FullVersion))
+}
+
+// modulePath returns the path of the module providing the package: the
+// longest module path of MajorVersions the package is in, otherwise one
+// inferred from the package path
+func (p *Package) modulePath() string {
+	var longest string
+	for _, modulePath := range p.MajorVersions {
+		if hasPathPrefix(p.Package, modulePath) && len(modulePath) > len(longest) {
+			longest = modulePath
+		}
+	}
+	if longest == "" {
+		return inferModulePath(p.Package)
+	}
+	return longest
+}
+
+// requireVersion returns the version of the package as required in a go.mod
+func (p *Package) requireVersion(modulePath string) (string, error) {
+	if p.Version == "" {
+		return "", fmt.Errorf("no version known for '%s'", p.Package)
+	}
+	if !semver.IsValid(p.Version) {
+		// pkg.go.dev abbreviates pseudo-versions in its header
+		return "", fmt.Errorf("'%s' is not a full version, describe the package with its full version", p.Version)
+	}
+	version := requireVersion(modulePath, p.Version)
+	if _, pathMajor, ok := module.SplitPathVersion(modulePath); ok {
+		if err := module.CheckPathMajor(version, pathMajor); err != nil {
+			return "", fmt.Errorf("version of '%s': %w", modulePath, err)
+		}
+	}
+	return version, nil
+}
+
+// RequireDirective returns the go.mod line requiring the package's module at
+// the described version, like "require example.com/mod/v2 v2.1.0"
+func (p *Package) RequireDirective() (string, error) {
+	modulePath := p.modulePath()
+	version, err := p.requireVersion(modulePath)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("require %s %s", modulePath, version), nil
+}
+
+// GoGetCommand returns the command adding the package at the described
+// version to a module, like "go get example.com/mod/v2/pkg@v2.1.0"
+func (p *Package) GoGetCommand() (string, error) {
+	version, err := p.requireVersion(p.modulePath())
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("go get %s@%s", p.Package, version), nil
+}
@@ -0,0 +1,26 @@
+package pkggodev
+
+import (
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// ResponseMeta describes the response a result was scraped from
+type ResponseMeta struct {
+	StatusCode int
+	FinalURL   string    // URL of the page after redirects
+	FetchedAt  time.Time // when the response was received, or answered by WithCache
+}
+
+// trackResponse records the response to the pages col visits in meta, taking
+// the time from now
+func trackResponse(col *colly.Collector, meta *ResponseMeta, now func() time.Time) {
+	col.OnResponse(func(r *colly.Response) {
+		*meta = ResponseMeta{
+			StatusCode: r.StatusCode,
+			FinalURL:   r.Request.URL.String(),
+			FetchedAt:  now(),
+		}
+	})
+}
@@ -0,0 +1,212 @@
+package pkggodev
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SearchIterator goes through the results of a search, fetching each page
+// of pkg.go.dev only once the results of the previous one are consumed
+type SearchIterator struct {
+	c   *client
+	req SearchRequest
+	ctx context.Context
+
+	next      string // query string of the next page, empty after the last one
+	pagesLeft int
+	limit     int
+	yielded   int
+	pending   []SearchResult // results of the last page not yet returned
+	done      bool
+	err       error
+
+	scanned   int
+	responses []ResponseMeta
+	warnings  []error
+	stats     *Stats
+}
+
+// SearchIter returns an iterator over the results of req, checked like
+// Search does. Results come in pkg.go.dev's relevance order: SortBy needs
+// every result and is refused. Nothing is fetched until the first call to
+// Next, and the iteration stops with an error when req.Context is done
+// before the next page.
+func (c *client) SearchIter(req SearchRequest) *SearchIterator {
+	it := &SearchIterator{c: c, req: req, ctx: req.Context}
+	if it.ctx == nil {
+		it.ctx = context.Background()
+	}
+	it.err = c.checkSearchRequest(req)
+	if it.err == nil && req.SortBy != SearchSortRelevance {
+		it.err = fmt.Errorf("%w: SortBy %s needs every result, use Search", ErrInvalidRequest, req.SortBy)
+	}
+	var query string
+	if it.err == nil {
+		query, it.err = normalizeQuery(req.Query)
+	}
+	if it.err == nil && req.Cursor != "" {
+		it.next, it.err = decodeSearchCursor(req.Cursor, query)
+		if it.err == nil && req.Page > 0 {
+			it.err = fmt.Errorf("%w: Cursor and Page are mutually exclusive", ErrInvalidRequest)
+		}
+	}
+	if it.err != nil {
+		it.done, it.next = true, ""
+		return it
+	}
+
+	limit, maxPages := req.Limit, req.MaxPages
+	if maxPages == 0 {
+		maxPages = c.maxPages
+	}
+	if limit <= 0 {
+		limit, maxPages = math.MaxInt, 1
+	}
+	page := 1
+	if req.Page > 0 {
+		page, maxPages = req.Page, 1
+	}
+	if it.next == "" {
+		it.next = fmt.Sprintf("q=%s&page=%d", url.QueryEscape(query), page)
+		if req.PageSize > 0 {
+			it.next += fmt.Sprintf("&limit=%d", req.PageSize)
+		}
+	}
+	it.pagesLeft, it.limit = maxPages, limit
+	if req.CollectStats {
+		it.stats = newStats()
+	}
+	return it
+}
+
+// decodeSearchCursor returns the query string of the page a cursor points
+// at, checking that it searches for query when that's not empty
+func decodeSearchCursor(cursor, query string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed Cursor: %v", ErrInvalidRequest, err)
+	}
+	values, err := url.ParseQuery(string(raw))
+	if err != nil || !values.Has("q") {
+		return "", fmt.Errorf("%w: malformed Cursor", ErrInvalidRequest)
+	}
+	if query != "" && values.Get("q") != query {
+		return "", fmt.Errorf("%w: Cursor searches for '%s', not '%s'", ErrInvalidRequest, values.Get("q"), query)
+	}
+	return string(raw), nil
+}
+
+// pageNumber returns the page a search query string asks for
+func pageNumber(rawQuery string) int {
+	values, _ := url.ParseQuery(rawQuery)
+	if page, err := strconv.Atoi(values.Get("page")); err == nil && page > 0 {
+		return page
+	}
+	return 1
+}
+
+// withPage returns a search query string asking for page instead, keeping
+// its other parameters in order
+func withPage(rawQuery string, page int) string {
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		if strings.HasPrefix(param, "page=") {
+			params[i] = "page=" + strconv.Itoa(page)
+			return strings.Join(params, "&")
+		}
+	}
+	return rawQuery + "&page=" + strconv.Itoa(page)
+}
+
+// NextCursor returns the cursor of the page after the last one fetched, to
+// continue the search later with SearchRequest.Cursor, or "" once there are
+// no more pages
+func (it *SearchIterator) NextCursor() string {
+	if it.next == "" {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(it.next))
+}
+
+// checkSearchRequest returns ErrInvalidRequest for invalid or conflicting
+// fields of req
+func (c *client) checkSearchRequest(req SearchRequest) error {
+	if c.baseURLErr != nil {
+		return c.baseURLErr
+	}
+	if req.ExcludeRetracted && req.OnlyRetracted {
+		return fmt.Errorf("%w: ExcludeRetracted and OnlyRetracted are mutually exclusive", ErrInvalidRequest)
+	}
+	if req.MaxPages < 0 {
+		return fmt.Errorf("%w: negative MaxPages %d", ErrInvalidRequest, req.MaxPages)
+	}
+	if req.PageSize < 0 || req.PageSize > maxSearchPageSize {
+		return fmt.Errorf("%w: PageSize %d out of range 0-%d", ErrInvalidRequest, req.PageSize, maxSearchPageSize)
+	}
+	if req.Page < 0 {
+		return fmt.Errorf("%w: negative Page %d", ErrInvalidRequest, req.Page)
+	}
+	return nil
+}
+
+// Next returns the next result, fetching the next page when the current one
+// is exhausted. It returns nil once there are no more results, or nil and
+// the error that stopped the iteration, which Err returns from then on.
+func (it *SearchIterator) Next() (*SearchResult, error) {
+	for len(it.pending) == 0 {
+		if it.done {
+			return nil, it.err
+		}
+		it.fetch()
+	}
+	result := it.pending[0]
+	it.pending = it.pending[1:]
+	it.yielded++
+	if it.yielded >= it.limit {
+		it.done, it.pending = true, nil
+	}
+	return &result, nil
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// fetch fetches the next page into pending, marking the iteration as done
+// after the last page
+func (it *SearchIterator) fetch() {
+	if it.pagesLeft == 0 || it.next == "" {
+		it.done = true
+		return
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.done, it.err = true, err
+		return
+	}
+	fetched, err := it.c.searchPage(it.ctx, it.req, it.c.baseURL+"/search?"+it.next, it.stats)
+	if err != nil {
+		it.done, it.err = true, fmt.Errorf("visiting page %d: %w", pageNumber(it.next), err)
+		return
+	}
+	it.pagesLeft--
+	// follow pkg.go.dev's own link to the next page, counting pages
+	// otherwise
+	if fetched.next != "" {
+		it.next = fetched.next
+	} else {
+		it.next = withPage(it.next, pageNumber(it.next)+1)
+	}
+	it.scanned += fetched.snippets
+	it.responses = append(it.responses, fetched.response)
+	it.warnings = append(it.warnings, fetched.warnings...)
+	it.pending = fetched.results
+	if fetched.snippets == 0 {
+		it.done, it.next = true, ""
+	}
+}
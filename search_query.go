@@ -0,0 +1,102 @@
+package pkggodev
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// searchOperators are the operators understood by pkg.go.dev's search, used
+// as name:value in a query
+var searchOperators = []string{"author", "description", "license"}
+
+// quoteOperatorValue quotes values with spaces so they stay one operand. The
+// syntax has no escapes, so double quotes inside a value are dropped.
+func quoteOperatorValue(value string) string {
+	value = strings.ReplaceAll(value, `"`, "")
+	if strings.IndexFunc(value, unicode.IsSpace) >= 0 {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// splitQuery splits a query at spaces outside of double quotes
+func splitQuery(query string) ([]string, error) {
+	var terms []string
+	var term strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			term.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if term.Len() > 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("%w: unterminated quote in query '%s'", ErrInvalidRequest, query)
+	}
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+	return terms, nil
+}
+
+// normalizeQuery checks the operators of a search query, returning it with
+// their names lowercased and their values quoted consistently. Terms are
+// operators when a name made of letters precedes the colon.
+func normalizeQuery(query string) (string, error) {
+	terms, err := splitQuery(query)
+	if err != nil {
+		return "", err
+	}
+	for i, term := range terms {
+		name, value, ok := strings.Cut(term, ":")
+		if !ok || name == "" || strings.IndexFunc(name, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+			continue
+		}
+		name = strings.ToLower(name)
+		if !slices.Contains(searchOperators, name) {
+			return "", fmt.Errorf("%w: unknown search operator '%s', expected one of %s", ErrInvalidRequest, name, strings.Join(searchOperators, ", "))
+		}
+		value = strings.Trim(value, `"`)
+		if strings.TrimSpace(value) == "" {
+			return "", fmt.Errorf("%w: empty value for search operator '%s'", ErrInvalidRequest, name)
+		}
+		terms[i] = name + ":" + quoteOperatorValue(value)
+	}
+	return strings.Join(terms, " "), nil
+}
+
+// BuildQuery builds a search query from operators, like "license" to "MIT",
+// followed by freeText. Operators are written in alphabetical order and
+// those with an empty value are left out; values with spaces are quoted.
+// Search rejects operators pkg.go.dev doesn't know.
+func BuildQuery(operators map[string]string, freeText string) string {
+	names := make([]string, 0, len(operators))
+	for name := range operators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var terms []string
+	for _, name := range names {
+		value := strings.TrimSpace(operators[name])
+		if value == "" {
+			continue
+		}
+		terms = append(terms, strings.ToLower(name)+":"+quoteOperatorValue(value))
+	}
+	if freeText = strings.TrimSpace(freeText); freeText != "" {
+		terms = append(terms, freeText)
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,190 @@
+package pkggodev
+
+// Selectors holds the CSS selectors used to scrape pkg.go.dev pages. When the
+// site's layout changes, WithSelectors can be used to fix scraping without
+// waiting for a new release: start from DefaultSelectors and override the
+// fields that broke.
+type Selectors struct {
+	// ImportedBy tab
+	ImportedBy            string
+	ImportedByGroup       string // collapsible list of a module's importers
+	ImportedByGroupModule string // heading of ImportedByGroup naming the module
+
+	// unit page, used by DescribePackage
+	UnitVersion         string
+	UnitLicenses        string
+	UnitMeta            string
+	UnitMetaChecked     string // matches a checked item in UnitMeta
+	UnitRepo            string
+	UnitCommitTime      string
+	UnitTitle           string
+	UnitMetaDescription string
+	UnitLatestBanner    string // badge or banner pointing at a newer version
+	UnitMajorVersions   string // links of the major version switcher
+	UnitImportedBy      string // "Imported by: N" link in the header
+	UnitImports         string // "Imports: N" link in the header
+	UnitBadge           string // badges like "deprecated" or "retracted"
+	UnitBanner          string // notices shown above the documentation
+	UnitVulnerability   string // links to vulnerability reports
+	UnitAuthor          string // author or maintainer metadata
+	DocOverview         string
+	DocIndexList        string // list of the documentation index, parent of the entries below
+	DocIndexConstants   string
+	DocIndexVariables   string
+	DocIndexFunction    string // function entry of the documentation index
+	DocIndexType        string
+	DocIndexMethod      string
+	DocExample          string // link of the examples list
+	DocConstant         string // the index only links the group, so names are counted
+	DocVariable         string
+	DocConstantBlock    string // declaration of a const block, with the names as [id] elements
+	DocVariableBlock    string
+	DocDeclaration      string // any declaration, ending the synopsis of the one before
+	DocPlatform         string // selected option of the GOOS/GOARCH dropdown
+	SourceFiles         string
+	SourceFilesDirLink  string
+	SourceFilesFileLink string
+	ReadmeImages        string
+	ReadmeTruncated     string // marker of a collapsed README
+	DocTruncated        string // marker of collapsed or cut short documentation
+
+	// Directories section of a module's page, selectors below
+	// UnitDirectoryRow are relative to it
+	UnitDirectoryRow      string
+	UnitDirectoryLink     string
+	UnitDirectorySynopsis string
+	UnitDirectoryBadge    string // "module" badge of nested modules
+
+	// versions tab, children of VersionsList are matched against the others
+	VersionsList      string
+	VersionMajor      string
+	VersionTag        string
+	VersionLink       string
+	VersionBadge      string // badges like "retracted" within VersionTag
+	VersionCommitTime string
+	VersionDetails    string
+	VersionSummary    string
+	// links to vulnerability reports within a version's row
+	VersionVulnerability string
+	// changes listed in VersionDetails, selectors below are relative to
+	// VersionChange except for VersionChangeImpacted, which matches siblings
+	// following it
+	VersionChange         string
+	VersionChangeSynopsis string
+	VersionChangeImpacted string // list of the packages broken by the change
+
+	// search results page, selectors below SearchSnippet are relative to it
+	SearchResults    string
+	SearchSnippet    string
+	SearchTitleLink  string
+	SearchSynopsis   string
+	SearchInfo       string
+	SearchPublished  string
+	SearchImportedBy string
+	SearchLicense    string
+	SearchBadge      string // badges like "retracted" or "deprecated"
+	SearchModule     string // module path, when the snippet names it
+
+	// options of the search form's license filter, holding SPDX identifiers
+	SearchLicenseFilter string
+	// link to the next page of search results
+	SearchNextPage string
+
+	// vulnerability search page, selectors below VulnEntry are relative to it
+	VulnEntry   string
+	VulnLink    string // link to the report, named after its ID
+	VulnSummary string
+	VulnAlias   string // CVE or GHSA identifier
+}
+
+// DefaultSelectors returns the selectors matching pkg.go.dev's current layout
+func DefaultSelectors() Selectors {
+	return Selectors{
+		ImportedBy:            ".u-breakWord",
+		ImportedByGroup:       ".ImportedBy-details",
+		ImportedByGroupModule: ".ImportedBy-detailsSummary",
+
+		UnitVersion:         "[data-test-id=UnitHeader-version]",
+		UnitLicenses:        "[data-test-id=UnitHeader-licenses]",
+		UnitMeta:            ".UnitMeta",
+		UnitMetaChecked:     "img[alt=checked]",
+		UnitRepo:            ".UnitMeta-repo",
+		UnitCommitTime:      "[data-test-id=UnitHeader-commitTime]",
+		UnitTitle:           ".UnitHeader-titleHeading",
+		UnitMetaDescription: "head meta[name=description], head meta[property='og:description']",
+		UnitLatestBanner:    "[data-test-id=UnitHeader-minorVersionBanner]",
+		UnitMajorVersions:   ".UnitHeader-majorVersions a[href]",
+		UnitImportedBy:      "[data-test-id=UnitHeader-importedby]",
+		UnitImports:         "[data-test-id=UnitHeader-imports]",
+		UnitBadge:           ".UnitHeader .go-Chip",
+		UnitBanner:          ".go-Message",
+		UnitVulnerability:   "a[href^='/vuln/GO-']",
+		UnitAuthor:          "head meta[name=author], head meta[name=maintainer]",
+		DocOverview:         ".Documentation-overview",
+		DocIndexList:        ".Documentation-indexList",
+		DocIndexConstants:   ".Documentation-indexConstants",
+		DocIndexVariables:   ".Documentation-indexVariables",
+		DocIndexFunction:    ".Documentation-indexFunction",
+		DocIndexType:        ".Documentation-indexType",
+		DocIndexMethod:      ".Documentation-indexMethod",
+		DocExample:          ".Documentation-examplesList a[href]",
+		DocConstant:         ".Documentation-constants [data-kind=constant]",
+		DocVariable:         ".Documentation-variables [data-kind=variable]",
+		DocConstantBlock:    ".Documentation-constants .Documentation-declaration, .Documentation-typeConstant .Documentation-declaration",
+		DocVariableBlock:    ".Documentation-variables .Documentation-declaration, .Documentation-typeVariable .Documentation-declaration",
+		DocDeclaration:      ".Documentation-declaration",
+		DocPlatform:         ".Documentation-buildContext select option[selected]",
+		SourceFiles:         ".UnitFiles",
+		SourceFilesDirLink:  ".UnitFiles-titleLink a[href]",
+		SourceFilesFileLink: ".UnitFiles-fileList a[href]",
+		ReadmeImages:        ".UnitReadme-content img",
+		ReadmeTruncated:     ".UnitReadme .js-readmeExpand",
+		DocTruncated:        ".Documentation .js-expandAllDocs, .Documentation-truncated",
+
+		UnitDirectoryRow:      ".UnitDirectories tbody tr",
+		UnitDirectoryLink:     "a[href]",
+		UnitDirectorySynopsis: ".UnitDirectories-desktopSynopsis",
+		UnitDirectoryBadge:    ".go-Chip",
+
+		VersionsList:      ".Versions-list",
+		VersionMajor:      ".Version-major",
+		VersionTag:        ".Version-tag",
+		VersionLink:       ".js-versionLink",
+		VersionBadge:      ".go-Chip",
+		VersionCommitTime: ".Version-commitTime",
+		VersionDetails:    ".Version-details",
+		VersionSummary:    ".Version-summary",
+
+		VersionVulnerability: "a[href*='/vuln/GO-']",
+
+		VersionChange:         ".Versions-symbol",
+		VersionChangeSynopsis: ".Versions-symbolSynopsis",
+		VersionChangeImpacted: ".Versions-symbolImpacted",
+
+		SearchResults:    ".SearchResults",
+		SearchSnippet:    ".SearchSnippet",
+		SearchTitleLink:  ".SearchSnippet-headerContainer a",
+		SearchSynopsis:   ".SearchSnippet-synopsis",
+		SearchInfo:       ".SearchSnippet-infoLabel",
+		SearchPublished:  "[data-test-id=snippet-published] strong",
+		SearchImportedBy: "a[href*='tab=importedby'] strong",
+		SearchLicense:    "[data-test-id=snippet-license]",
+		SearchBadge:      ".SearchSnippet-headerContainer .go-Chip",
+		SearchModule:     "[data-test-id=snippet-module]",
+
+		SearchLicenseFilter: "form[action='/search'] select[name=license] option, form[action='/search'] input[name=license]",
+		SearchNextPage:      ".Pagination-next[href]",
+
+		VulnEntry:   ".VulnList-entry",
+		VulnLink:    "a[href*='/vuln/GO-']",
+		VulnSummary: ".VulnList-summary",
+		VulnAlias:   ".VulnList-alias",
+	}
+}
+
+// WithSelectors replaces the selectors used to scrape pkg.go.dev
+func WithSelectors(s Selectors) func(c *client) {
+	return func(c *client) {
+		c.selectors = s
+	}
+}
@@ -0,0 +1,202 @@
+package pkggodev
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// FieldError reports that a single field of a result couldn't be determined.
+// Field names the result's field, or the page when a whole fetch failed.
+type FieldError struct {
+	Field string
+	Raw   string // text of the page that couldn't be parsed, if any
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// errElementMissing is reported for fields whose element isn't on the page
+var errElementMissing = errors.New("element not found on page")
+
+// Snapshot is a summary of a package's health, as shown on a dashboard
+type Snapshot struct {
+	Package          string
+	Version          string
+	Published        string
+	ImportedByCount  int
+	ImportsCount     int
+	Licenses         []string
+	HasTaggedVersion bool
+	HasStableVersion bool
+	Deprecated       bool
+	Retracted        bool
+	Vulnerabilities  []string // IDs like GO-2022-0123
+	// Errors lists the fields that couldn't be determined, which are left
+	// at their zero value
+	Errors []*FieldError
+}
+
+// countPattern matches a count like "1,234" in the unit header
+var countPattern = regexp.MustCompile(`[0-9][0-9,]*`)
+
+func parseCount(text string) (int, error) {
+	match := countPattern.FindString(text)
+	if match == "" {
+		return 0, fmt.Errorf("no count in '%s'", strings.TrimSpace(text))
+	}
+	return strconv.Atoi(strings.ReplaceAll(match, ",", ""))
+}
+
+// Snapshot gathers a package's health record from its unit page and versions
+// tab, fetched concurrently. Failures are reported per field in
+// Snapshot.Errors so that a partial record is still returned; an error is
+// only returned when nothing could be fetched at all.
+func (c *client) Snapshot(pkg string) (*Snapshot, error) {
+	snap := &Snapshot{Package: pkg}
+	var versions *Versions
+	var unitErr, versionsErr error
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		unitErr = c.scrapeSnapshotUnit(snap)
+	}()
+	go func() {
+		defer wg.Done()
+		versions, versionsErr = c.Versions(VersionsRequest{Package: pkg})
+	}()
+	wg.Wait()
+
+	if unitErr != nil && versionsErr != nil {
+		return nil, unitErr
+	}
+	if unitErr != nil {
+		snap.Errors = append(snap.Errors, &FieldError{Field: "unit page", Err: unitErr})
+	}
+	if versionsErr != nil {
+		snap.Errors = append(snap.Errors, &FieldError{Field: "versions tab", Err: versionsErr})
+	}
+
+	// the unit header is authoritative, the versions tab fills in the gaps
+	if versions != nil && (snap.Version == "" || snap.Published == "") {
+		if latest, ok := versions.Latest(); ok {
+			if snap.Version == "" {
+				snap.Version = latest.FullVersion
+			}
+			if snap.Published == "" {
+				snap.Published = latest.Date
+			}
+		}
+	}
+	if snap.Version == "" {
+		snap.Errors = append(snap.Errors, &FieldError{Field: "Version", Err: errElementMissing})
+	}
+	if snap.Published == "" {
+		snap.Errors = append(snap.Errors, &FieldError{Field: "Published", Err: errElementMissing})
+	}
+	return snap, nil
+}
+
+// scrapeSnapshotUnit fills in the fields of snap found on the unit page
+func (c *client) scrapeSnapshotUnit(snap *Snapshot) error {
+	if c.baseURLErr != nil {
+		return c.baseURLErr
+	}
+	col := c.newCollector()
+	sel := c.selectors
+	var err error
+	found := map[string]bool{}
+	addErr := func(field string, e error) {
+		snap.Errors = append(snap.Errors, &FieldError{Field: field, Err: e})
+	}
+
+	col.OnHTML(sel.UnitVersion, func(e *colly.HTMLElement) {
+		snap.Version = unitHeaderVersion(e)
+	})
+	col.OnHTML(sel.UnitCommitTime, func(e *colly.HTMLElement) {
+		dateStr := strings.TrimPrefix(strings.TrimSpace(e.Text), "Published: ")
+		published, err := normalizeTime(dateStr, c.now())
+		if err != nil {
+			addErr("Published", err)
+			return
+		}
+		snap.Published = published
+	})
+	col.OnHTML(sel.UnitLicenses, func(e *colly.HTMLElement) {
+		found["Licenses"] = true
+		snap.Licenses = splitLicenses(unitHeaderLicense(e))
+	})
+	col.OnHTML(sel.UnitMeta, func(e *colly.HTMLElement) {
+		found["UnitMeta"] = true
+		checks := unitMetaChecks(e, sel)
+		snap.HasTaggedVersion = checks[2]
+		snap.HasStableVersion = checks[3]
+	})
+	col.OnHTML(sel.UnitImportedBy, func(e *colly.HTMLElement) {
+		found["ImportedByCount"] = true
+		count, err := parseCount(e.Text)
+		if err != nil {
+			addErr("ImportedByCount", err)
+			return
+		}
+		snap.ImportedByCount = count
+	})
+	col.OnHTML(sel.UnitImports, func(e *colly.HTMLElement) {
+		found["ImportsCount"] = true
+		count, err := parseCount(e.Text)
+		if err != nil {
+			addErr("ImportsCount", err)
+			return
+		}
+		snap.ImportsCount = count
+	})
+	col.OnHTML(sel.UnitBadge, func(e *colly.HTMLElement) {
+		switch strings.ToLower(strings.TrimSpace(e.Text)) {
+		case "deprecated":
+			snap.Deprecated = true
+		case "retracted":
+			snap.Retracted = true
+		}
+	})
+	col.OnHTML(sel.UnitVulnerability, func(e *colly.HTMLElement) {
+		id := path.Base(e.Attr("href"))
+		for _, known := range snap.Vulnerabilities {
+			if known == id {
+				return
+			}
+		}
+		snap.Vulnerabilities = append(snap.Vulnerabilities, id)
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		if r.StatusCode == 404 {
+			err = ErrNotFound
+			return
+		}
+		err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
+	})
+
+	col.Visit(c.pageURL(snap.Package))
+	if err != nil {
+		return err
+	}
+	for _, field := range []string{"Licenses", "UnitMeta", "ImportedByCount", "ImportsCount"} {
+		if !found[field] {
+			addErr(field, errElementMissing)
+		}
+	}
+	return nil
+}
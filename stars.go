@@ -0,0 +1,100 @@
+package pkggodev
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// WithConcurrency sets how many repository pages PackagesByStar fetches at
+// once, defaulting to 4. Values below 1 keep the default.
+func WithConcurrency(n int) func(c *client) {
+	return func(c *client) {
+		c.concurrency = n
+	}
+}
+
+// PackagesByStar returns the popular packages, as found by TopPackages,
+// whose repository has between minStars and maxStars stars, both included,
+// with Stars set. Stars are only known for GitHub and GitLab repositories,
+// including those behind vanity import paths.
+//
+// This is expensive: on top of the search, the repository page of every
+// result is fetched, a few at once as set with WithConcurrency. Results
+// whose stars couldn't be fetched are left out and the failures returned in
+// an ErrorList alongside the others.
+func (c *client) PackagesByStar(ctx context.Context, minStars, maxStars int) (*SearchResults, error) {
+	if minStars < 0 || maxStars < minStars {
+		return nil, fmt.Errorf("%w: star range [%d, %d]", ErrInvalidRequest, minStars, maxStars)
+	}
+	if c.pkgGoDevOnly {
+		return nil, ErrExternalFetchDisabled
+	}
+
+	errs := &ErrorList{}
+	candidates, err := c.TopPackages(ctx, maxSearchPageSize)
+	if err != nil {
+		if candidates == nil {
+			return nil, err
+		}
+		errs.Errs = append(errs.Errs, err)
+	}
+
+	concurrency := c.concurrency
+	if concurrency < 1 {
+		concurrency = defaultBatchConcurrency
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	starred := make([]bool, len(candidates.Results))
+	results := candidates.Results
+
+	for i, result := range results {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			repoURL := result.GitRepository
+			if repoURL == "" {
+				// vanity paths have no inferred repository, their go-import
+				// meta tag names it
+				repoURL = c.hostedRepository(result.Package, "https://"+result.Package)
+			}
+			if hostType := c.identifyGitHost(normalizeRepoURL(repoURL)); hostType != GitHostGitHub && hostType != GitHostGitLab {
+				mu.Lock()
+				errs.Errs = append(errs.Errs, fmt.Errorf("fetching stars of '%s': no GitHub or GitLab repository", result.Package))
+				mu.Unlock()
+				return
+			}
+			info, err := c.fetchRepoInfo(repoURL, SprinkleNoNetworkCounts)
+			if err != nil {
+				mu.Lock()
+				errs.Errs = append(errs.Errs, fmt.Errorf("fetching stars of '%s': %w", result.Package, err))
+				mu.Unlock()
+				return
+			}
+			results[i].Stars = info.stars
+			starred[i] = info.stars >= minStars && info.stars <= maxStars
+		}()
+	}
+	wg.Wait()
+
+	filtered := &SearchResults{Scanned: candidates.Scanned, Stats: candidates.Stats, Responses: candidates.Responses}
+	for i, result := range results {
+		if starred[i] {
+			filtered.Results = append(filtered.Results, result)
+		}
+	}
+	if len(errs.Errs) > 0 {
+		return filtered, errs
+	}
+	return filtered, nil
+}
@@ -0,0 +1,65 @@
+package pkggodev
+
+import (
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// Stats describes how expensive a single call was. It's only populated when
+// the request sets CollectStats.
+type Stats struct {
+	PagesFetched    int
+	BytesDownloaded int64
+	Duration        time.Duration
+	URLDurations    map[string]time.Duration
+
+	start time.Time
+}
+
+// statsStartKey is the colly request context key holding the request start time
+const statsStartKey = "pkggodev.statsStart"
+
+// trackStats starts collecting stats for every request made by col
+func trackStats(col *colly.Collector) *Stats {
+	stats := newStats()
+	stats.track(col)
+	return stats
+}
+
+func newStats() *Stats {
+	return &Stats{
+		URLDurations: map[string]time.Duration{},
+		start:        time.Now(),
+	}
+}
+
+// track adds the requests made by col to the stats, so that calls using
+// several collectors can share one Stats
+func (s *Stats) track(col *colly.Collector) {
+	col.OnRequest(func(r *colly.Request) {
+		r.Ctx.Put(statsStartKey, time.Now())
+	})
+	col.OnResponse(func(r *colly.Response) {
+		s.record(r)
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		s.record(r)
+	})
+}
+
+func (s *Stats) record(r *colly.Response) {
+	if start, ok := r.Ctx.GetAny(statsStartKey).(time.Time); ok {
+		s.URLDurations[r.Request.URL.String()] += time.Since(start)
+	}
+	if r.StatusCode != 0 {
+		s.PagesFetched++
+	}
+	s.BytesDownloaded += int64(len(r.Body))
+}
+
+// finish records the total wall time of the call
+func (s *Stats) finish() *Stats {
+	s.Duration = time.Since(s.start)
+	return s
+}
@@ -0,0 +1,46 @@
+package pkggodev
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// successorNotice matches the text of banners naming a module's successor,
+// like "Module github.com/foo/bar is now available" or the deprecation
+// message of its go.mod, "Deprecated: use github.com/foo/baz instead"
+var successorNotice = regexp.MustCompile(`(?i)now available|moved to|deprecated`)
+
+// successorInText finds the module path named by a deprecation message
+// without a link, like "Deprecated: use github.com/golang-jwt/jwt instead"
+var successorInText = regexp.MustCompile(`(?i)(?:use|moved to|available at|replaced by|in favou?r of)\s+([a-z0-9][a-z0-9.\-]*\.[a-z]{2,}(?:/[\w.\-~]+)+)`)
+
+// bannerSuccessor returns the module path a banner of the page of pkgPath
+// points at as its successor, or "" when the banner isn't such a notice
+func bannerSuccessor(e *colly.HTMLElement, pkgPath string) string {
+	text := strings.Join(strings.Fields(e.Text), " ")
+	if !successorNotice.MatchString(text) {
+		return ""
+	}
+	var candidates []string
+	e.ForEach("a[href]", func(_ int, a *colly.HTMLElement) {
+		link, err := url.Parse(a.Attr("href"))
+		if err != nil || (link.Host != "" && link.Host != e.Request.URL.Host) {
+			return
+		}
+		candidates = append(candidates, strings.Trim(link.Path, "/"))
+	})
+	if m := successorInText.FindStringSubmatch(text); m != nil {
+		candidates = append(candidates, strings.TrimRight(m[1], "."))
+	}
+	for _, candidate := range candidates {
+		candidate = directoryVersionPattern.ReplaceAllString(candidate, "")
+		// skip links outside of package pages, and to the package itself
+		if strings.Contains(strings.SplitN(candidate, "/", 2)[0], ".") && !hasPathPrefix(pkgPath, candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
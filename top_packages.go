@@ -0,0 +1,100 @@
+package pkggodev
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// topPackageSeeds are widely imported packages, ranked by TopPackages when
+// pkg.go.dev doesn't answer an empty search
+var topPackageSeeds = []string{
+	"github.com/stretchr/testify/assert",
+	"github.com/stretchr/testify/require",
+	"github.com/google/uuid",
+	"github.com/pkg/errors",
+	"github.com/sirupsen/logrus",
+	"github.com/spf13/cobra",
+	"github.com/spf13/pflag",
+	"github.com/spf13/viper",
+	"github.com/golang/protobuf/proto",
+	"google.golang.org/protobuf/proto",
+	"google.golang.org/grpc",
+	"gopkg.in/yaml.v2",
+	"gopkg.in/yaml.v3",
+	"github.com/gorilla/mux",
+	"github.com/davecgh/go-spew/spew",
+	"go.uber.org/zap",
+	"golang.org/x/sys/unix",
+	"golang.org/x/net/context",
+	"github.com/prometheus/client_golang/prometheus",
+	"k8s.io/apimachinery/pkg/apis/meta/v1",
+}
+
+// TopPackages returns the limit most imported packages, sorted by their
+// imported by count. pkg.go.dev has no such ranking of its own, so an empty
+// search is tried first and, when it yields nothing, a list of known popular
+// packages is ranked by the counts their search results report. Lookups that
+// failed are returned in an ErrorList alongside the ranking.
+func (c *client) TopPackages(ctx context.Context, limit int) (*SearchResults, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("%w: limit must be positive, got %d", ErrInvalidRequest, limit)
+	}
+
+	results, err := c.Search(SearchRequest{Query: "", Limit: limit, Context: ctx})
+	if err == nil && len(results.Results) > 0 {
+		results.SortBy("importedby", false)
+		return results, nil
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := &ErrorList{}
+	found := make([]*SearchResult, len(topPackageSeeds))
+	sem := make(chan struct{}, defaultBatchConcurrency)
+
+	for i, seed := range topPackageSeeds {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			seedResults, err := c.Search(SearchRequest{Query: seed, Limit: 5, Context: ctx})
+			if err != nil {
+				mu.Lock()
+				errs.Errs = append(errs.Errs, fmt.Errorf("looking up '%s': %w", seed, err))
+				mu.Unlock()
+				return
+			}
+			for _, result := range seedResults.Results {
+				if result.Package == seed {
+					found[i] = &result
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	ranking := &SearchResults{}
+	for _, result := range found {
+		if result != nil {
+			ranking.Results = append(ranking.Results, *result)
+		}
+	}
+	ranking.SortBy("importedby", false)
+	if len(ranking.Results) > limit {
+		ranking.Results = ranking.Results[:limit]
+	}
+
+	if len(errs.Errs) > 0 {
+		return ranking, errs
+	}
+	return ranking, nil
+}
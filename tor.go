@@ -0,0 +1,51 @@
+package pkggodev
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultTorProxyURL is the SOCKS5 proxy of a Tor daemon with its default
+// configuration
+const defaultTorProxyURL = "socks5://127.0.0.1:9050"
+
+// WithTorProxy sends every request, including those to repository hosts and
+// the module proxy, through the Tor SOCKS5 proxy at torProxyURL, or
+// socks5://127.0.0.1:9050 when it's empty. Host names are resolved by Tor
+// rather than locally. Tor is slow, so combine it with a longer WithTimeout.
+//
+// The transport of a WithHTTPClient client is only kept when it's an
+// *http.Transport, other round trippers could dial around the proxy.
+// pkg.go.dev has no .onion address: reaching a mirror on one with
+// WithBaseURL also needs the Tor daemon to allow it, and Go's own resolver
+// refuses .onion names, so the proxy must be the only way out.
+func WithTorProxy(torProxyURL string) func(c *client) {
+	return func(c *client) {
+		if torProxyURL == "" {
+			torProxyURL = defaultTorProxyURL
+		}
+		if !strings.Contains(torProxyURL, "://") {
+			torProxyURL = "socks5://" + torProxyURL
+		}
+		u, err := url.Parse(torProxyURL)
+		if err != nil {
+			// dialing the unparsed address fails every request instead of
+			// silently bypassing the proxy
+			u = &url.URL{Scheme: "socks5", Host: torProxyURL}
+		}
+		c.torProxy = u
+	}
+}
+
+// torTransport returns a copy of transport sending its requests through
+// proxy. Round trippers other than *http.Transport are replaced.
+func torTransport(transport http.RoundTripper, proxy *url.URL) http.RoundTripper {
+	base, ok := transport.(*http.Transport)
+	if !ok {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	base = base.Clone()
+	base.Proxy = http.ProxyURL(proxy)
+	return base
+}
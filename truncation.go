@@ -0,0 +1,39 @@
+package pkggodev
+
+import (
+	"errors"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// ErrDocTruncated is returned alongside partial results when pkg.go.dev
+// collapsed or cut short the documentation they were read from
+var ErrDocTruncated = errors.New("documentation truncated on pkg.go.dev")
+
+// truncationMarker is an element showing that a section of a page was
+// collapsed or cut short
+type truncationMarker struct {
+	section  string
+	selector string
+}
+
+func truncationMarkers(sel Selectors) []truncationMarker {
+	return []truncationMarker{
+		{section: "documentation", selector: sel.DocTruncated},
+		{section: "readme", selector: sel.ReadmeTruncated},
+	}
+}
+
+// onTruncation calls mark once with the name of each section of the page
+// carrying a truncation marker
+func onTruncation(col *colly.Collector, sel Selectors, mark func(section string)) {
+	for _, marker := range truncationMarkers(sel) {
+		seen := false
+		col.OnHTML(marker.selector, func(*colly.HTMLElement) {
+			if !seen {
+				seen = true
+				mark(marker.section)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package pkggodev
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+// ValueBlock is a const or var declaration of a package, holding every name
+// it declares, so an iota-based group is a single block
+type ValueBlock struct {
+	Kind        string // "constant" or "variable"
+	Names       []string
+	Declaration string // full declaration text, values included
+	Synopsis    string
+}
+
+// Values lists the const and var declarations of pkg, including the ones
+// grouped with a type, in the order of the documentation. When the
+// documentation was truncated on pkg.go.dev, the blocks found are returned
+// along with ErrDocTruncated.
+func (c *client) Values(ctx context.Context, pkg string) ([]ValueBlock, error) {
+	if c.baseURLErr != nil {
+		return nil, c.baseURLErr
+	}
+	col := c.newCollector()
+	col.Context = ctx
+	sel := c.selectors
+	var blocks []ValueBlock
+	var err error
+	truncated := false
+
+	onTruncation(col, sel, func(section string) {
+		truncated = truncated || section == "documentation"
+	})
+	col.OnHTML(sel.DocConstantBlock+", "+sel.DocVariableBlock, func(e *colly.HTMLElement) {
+		block := ValueBlock{
+			Kind:        "variable",
+			Declaration: declarationText(e.DOM),
+			Synopsis:    strings.TrimSpace(e.DOM.NextUntil(sel.DocDeclaration).Filter("p").First().Text()),
+		}
+		if e.DOM.Is(sel.DocConstantBlock) {
+			block.Kind = "constant"
+		}
+		e.DOM.Find("[id]").Each(func(_ int, name *goquery.Selection) {
+			block.Names = append(block.Names, name.AttrOr("id", ""))
+		})
+		blocks = append(blocks, block)
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		if r.StatusCode == 404 {
+			err = ErrNotFound
+			return
+		}
+		err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
+	})
+	col.Visit(c.pageURL(pkg))
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		return blocks, ErrDocTruncated
+	}
+	return blocks, nil
+}
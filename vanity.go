@@ -0,0 +1,79 @@
+package pkggodev
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// ErrNoGoImport is returned by ResolveVanityImport when the page doesn't
+// declare where the import path's repository lives
+var ErrNoGoImport = errors.New("no go-import meta tag matching the import path")
+
+// VanityInfo is what a vanity import path's go-import and go-source meta
+// tags declare, see https://pkg.go.dev/cmd/go#hdr-Remote_import_paths
+type VanityInfo struct {
+	ImportPrefix string // module or repository root the tags apply to
+	VCS          string // e.g. "git" or "mod"
+	RepoRoot     string
+	// From go-source, empty when the page has none. The templates use the
+	// {dir}, {file} and {line} placeholders.
+	Home              string
+	DirectoryTemplate string
+	FileTemplate      string
+}
+
+// matchesImportPrefix reports whether importPath is prefix or below it
+func matchesImportPrefix(importPath, prefix string) bool {
+	return importPath == prefix || strings.HasPrefix(importPath, prefix+"/")
+}
+
+// ResolveVanityImport fetches importPath the way the go command does, with
+// ?go-get=1, and parses its go-import and go-source meta tags
+func (c *client) ResolveVanityImport(importPath string) (*VanityInfo, error) {
+	if c.pkgGoDevOnly {
+		return nil, ErrExternalFetchDisabled
+	}
+
+	col := c.newCollector()
+	var info *VanityInfo
+	var sourceFields []string
+	var err error
+
+	col.OnHTML("meta[name=go-import]", func(e *colly.HTMLElement) {
+		fields := strings.Fields(e.Attr("content"))
+		if info != nil || len(fields) != 3 || !matchesImportPrefix(importPath, fields[0]) {
+			return
+		}
+		info = &VanityInfo{ImportPrefix: fields[0], VCS: fields[1], RepoRoot: fields[2]}
+	})
+	col.OnHTML("meta[name=go-source]", func(e *colly.HTMLElement) {
+		fields := strings.Fields(e.Attr("content"))
+		if sourceFields != nil || len(fields) != 4 || !matchesImportPrefix(importPath, fields[0]) {
+			return
+		}
+		sourceFields = fields
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
+	})
+
+	visitErr := col.Visit(fmt.Sprintf("https://%s?go-get=1", importPath))
+	if err == nil && visitErr != nil {
+		err = fmt.Errorf("visiting %s: %w", importPath, visitErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, ErrNoGoImport
+	}
+	if sourceFields != nil && sourceFields[0] == info.ImportPrefix {
+		info.Home = sourceFields[1]
+		info.DirectoryTemplate = sourceFields[2]
+		info.FileTemplate = sourceFields[3]
+	}
+	return info, nil
+}
@@ -0,0 +1,54 @@
+package pkggodev
+
+import (
+	"fmt"
+	"regexp"
+
+	"golang.org/x/mod/semver"
+)
+
+// ErrMalformedVersion reports a scraped version that isn't a valid semantic
+// version, returned with WithStrictVersions and warned about otherwise
+type ErrMalformedVersion struct {
+	Raw string // text of the version as scraped
+}
+
+func (e ErrMalformedVersion) Error() string {
+	return fmt.Sprintf("malformed version '%s'", e.Raw)
+}
+
+// WithStrictVersions fails DescribePackage, Versions and Search when a scraped
+// version isn't valid semver, instead of keeping the text as is and adding a
+// warning. Pseudo-versions, +incompatible versions and the abbreviated
+// pseudo-versions of pkg.go.dev's header are valid.
+func WithStrictVersions() func(c *client) {
+	return func(c *client) {
+		c.strictVersions = true
+	}
+}
+
+// abbreviatedPseudoVersion matches pseudo-versions as abbreviated in the unit
+// header, like v0.0.0-...-496545a
+var abbreviatedPseudoVersion = regexp.MustCompile(`^v[0-9]+\.[0-9]+\.[0-9]+-\.\.\.-[0-9a-f]+$`)
+
+// checkVersion returns ErrMalformedVersion when raw isn't a version
+func checkVersion(raw string) error {
+	if semver.IsValid(raw) || abbreviatedPseudoVersion.MatchString(raw) {
+		return nil
+	}
+	return ErrMalformedVersion{Raw: raw}
+}
+
+// reportVersion checks a scraped version, returning the error to fail with in
+// strict mode or adding a warning to warnings otherwise
+func (c *client) reportVersion(raw string, warnings *[]error) error {
+	err := checkVersion(raw)
+	if err == nil {
+		return nil
+	}
+	if c.strictVersions {
+		return err
+	}
+	*warnings = append(*warnings, &FieldError{Field: "Version", Raw: raw, Err: err})
+	return nil
+}
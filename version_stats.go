@@ -0,0 +1,74 @@
+package pkggodev
+
+import (
+	"sort"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// VersionStats describes the release history of a module
+type VersionStats struct {
+	Releases     int // versions the stats were computed from
+	FirstRelease time.Time
+	LastRelease  time.Time
+	Age          time.Duration // since the first release
+	SinceLast    time.Duration // since the last release
+	// MedianInterval is the median time between consecutive releases, zero
+	// with fewer than two releases
+	MedianInterval   time.Duration
+	ReleasesLastYear int
+	// Excluded counts the versions left out because their date couldn't be
+	// parsed
+	Excluded int
+}
+
+// ReleaseStats computes the release history of the versions, leaving out
+// retracted versions and, unless includePseudo is set, pseudo-versions. Age,
+// SinceLast and ReleasesLastYear are relative to now, like Response.FetchedAt
+// to follow the client's clock and WithTimezone, or time.Now().
+func (v *Versions) ReleaseStats(includePseudo bool, now time.Time) VersionStats {
+	var stats VersionStats
+	var dates []time.Time
+	for _, ver := range v.Versions {
+		if ver.Retracted || (!includePseudo && module.IsPseudoVersion(ver.FullVersion)) {
+			continue
+		}
+		date, err := ver.PublishedTime()
+		if err != nil {
+			stats.Excluded++
+			continue
+		}
+		dates = append(dates, date)
+	}
+	stats.Releases = len(dates)
+	if len(dates) == 0 {
+		return stats
+	}
+
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	stats.FirstRelease, stats.LastRelease = dates[0], dates[len(dates)-1]
+	stats.Age = now.Sub(stats.FirstRelease)
+	stats.SinceLast = now.Sub(stats.LastRelease)
+	yearAgo := now.AddDate(-1, 0, 0)
+	for _, date := range dates {
+		if date.After(yearAgo) {
+			stats.ReleasesLastYear++
+		}
+	}
+
+	if len(dates) < 2 {
+		return stats
+	}
+	intervals := make([]time.Duration, len(dates)-1)
+	for i := range intervals {
+		intervals[i] = dates[i+1].Sub(dates[i])
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	mid := len(intervals) / 2
+	stats.MedianInterval = intervals[mid]
+	if len(intervals)%2 == 0 {
+		stats.MedianInterval = (intervals[mid-1] + intervals[mid]) / 2
+	}
+	return stats
+}
@@ -0,0 +1,119 @@
+package pkggodev
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+// defaultPollInterval is used unless changed with WithPollInterval
+const defaultPollInterval = 5 * time.Minute
+
+// WithPollInterval sets how often SubscribeToVulnerabilities polls for new
+// reports. Values below or equal to zero are ignored.
+func WithPollInterval(d time.Duration) func(c *client) {
+	return func(c *client) {
+		if d > 0 {
+			c.pollInterval = d
+		}
+	}
+}
+
+// Vulnerability is an entry of the Go vulnerability database
+type Vulnerability struct {
+	ID      string // like GO-2022-0123
+	Module  string // module whose search listed the report
+	Summary string
+	Aliases []string // CVE and GHSA identifiers of the same report
+	URL     string
+}
+
+// Vulnerabilities lists the vulnerability reports pkg.go.dev finds for a
+// module
+func (c *client) Vulnerabilities(ctx context.Context, modulePath string) ([]Vulnerability, error) {
+	if c.baseURLErr != nil {
+		return nil, c.baseURLErr
+	}
+	col := c.newCollector()
+	col.Context = ctx
+	sel := c.selectors
+	var vulns []Vulnerability
+	var err error
+
+	col.OnHTML(sel.VulnEntry, func(e *colly.HTMLElement) {
+		href, ok := e.DOM.Find(sel.VulnLink).First().Attr("href")
+		if !ok {
+			return
+		}
+		vuln := Vulnerability{
+			ID:      path.Base(href),
+			Module:  modulePath,
+			Summary: strings.TrimSpace(e.DOM.Find(sel.VulnSummary).First().Text()),
+			URL:     resolveURL(e.Request.URL, href),
+		}
+		e.DOM.Find(sel.VulnAlias).Each(func(_ int, s *goquery.Selection) {
+			if alias := strings.TrimSpace(s.Text()); alias != "" {
+				vuln.Aliases = append(vuln.Aliases, alias)
+			}
+		})
+		vulns = append(vulns, vuln)
+	})
+	col.OnError(func(r *colly.Response, e error) {
+		if r.StatusCode == 404 {
+			err = ErrNotFound
+			return
+		}
+		err = fmt.Errorf("making req to %s: %w", r.Request.URL.String(), e)
+	})
+	col.Visit(fmt.Sprintf("%s/vuln/search?q=%s", c.baseURL, url.QueryEscape(modulePath)))
+	if err != nil {
+		return nil, err
+	}
+	return vulns, nil
+}
+
+// SubscribeToVulnerabilities polls the vulnerability reports of modules and
+// sends each report to ch the first time its ID is seen, so reports already
+// published when subscribing are sent by the first poll. Polls happen every 5
+// minutes unless changed with WithPollInterval; a module whose lookup fails is
+// retried on the next poll. It blocks until ctx is done and returns ctx.Err().
+func (c *client) SubscribeToVulnerabilities(ctx context.Context, modules []string, ch chan<- Vulnerability) error {
+	if len(modules) == 0 {
+		return fmt.Errorf("%w: no modules to watch", ErrInvalidRequest)
+	}
+
+	seen := map[string]bool{}
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		for _, mod := range modules {
+			vulns, err := c.Vulnerabilities(ctx, mod)
+			if err != nil {
+				continue
+			}
+			for _, vuln := range vulns {
+				if seen[vuln.ID] {
+					continue
+				}
+				select {
+				case ch <- vuln:
+					seen[vuln.ID] = true
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}